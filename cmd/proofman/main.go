@@ -13,14 +13,21 @@ func main() {
 		Name:  "proofman",
 		Usage: "Dependency manager and utility tool for Isabelle",
 		Commands: []*cli.Command{
+			commands.BuildCommand,
+			commands.FmtCommand,
 			commands.IndexAfpCommand,
+			commands.InfoCommand,
 			commands.InitCommand,
+			commands.InstallCommand,
+			commands.LockCommand,
+			commands.OutdatedCommand,
+			commands.SearchCommand,
+			commands.TidyCommand,
+			commands.TreeCommand,
+			commands.UninstallCommand,
+			commands.UpdateCommand,
 			commands.VersionCommand,
-			// install <package> <version> [use lockfile?]
-			// uninstall <package>
-			// lock?
-			// tree
-			// go mod tidy equivalent?
+			commands.WhyCommand,
 		},
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
@@ -35,6 +42,12 @@ func main() {
 				Usage:    "Show more logging output",
 				Category: "Logging",
 			},
+			&cli.StringFlag{
+				Name:     "index-url",
+				Usage:    "The `INDEX_REPO_URL` to fetch packages from",
+				EnvVars:  []string{"PROOFMAN_INDEX_URL"},
+				Category: "Index",
+			},
 		},
 		Before: func(cCtx *cli.Context) error {
 			if cCtx.Bool("quiet") {
@@ -43,6 +56,9 @@ func main() {
 			if cCtx.Bool("verbose") {
 				internal.LogLevel = internal.LogLvlVerbose
 			}
+			if cCtx.IsSet("index-url") {
+				internal.IndexRepositoryUrl = cCtx.String("index-url")
+			}
 
 			return nil
 		},
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/isabelle"
+	"github.com/urfave/cli/v2"
+	"os"
+	"path/filepath"
+)
+
+func build(cCtx *cli.Context) error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	_, graph, err := projectGraph(pwd, cfg)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := graphManifests(pwd, graph)
+	if err != nil {
+		return err
+	}
+
+	p, err := projectPlan(pwd, manifests)
+	if err != nil {
+		return err
+	}
+
+	if cCtx.Bool("plan") {
+		printPlan(p)
+		return nil
+	}
+
+	venvRoot := filepath.Join(pwd, internal.VenvDirName)
+	for i, level := range p.Sessions {
+		sessions := make([]string, 0, len(level))
+		for _, session := range level {
+			sessions = append(sessions, session.Name)
+		}
+
+		logging.Unquiet("building level %d of %d (%d session(s))", i+1, len(p.Sessions), len(sessions))
+		if err = isabelle.Build(venvRoot, []string{pwd}, sessions); err != nil {
+			return fmt.Errorf("failed to build level %d - %s", i+1, err)
+		}
+	}
+
+	logging.Unquiet("built %d level(s) successfully", len(p.Sessions))
+
+	return nil
+}
+
+var BuildCommand = &cli.Command{
+	Name:   "build",
+	Usage:  "Builds the sessions of the current project and its dependencies, in dependency order",
+	Action: build,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "plan",
+			Usage: "Print the install and build plan without building anything",
+		},
+	},
+}
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/lockfile"
+	"github.com/tandemdude/proofman/pkg/venv"
+	"github.com/urfave/cli/v2"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func mustRequirement(t *testing.T, input string) config.Requirement {
+	req, err := config.ParseRequirement(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return req
+}
+
+func gitCommand(t *testing.T, directory string, args ...string) {
+	args = append([]string{"-c", "user.name=proofman", "-c", "user.email=proofman@example.com"}, args...)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = directory
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed - %s\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+// testIndex creates an index repository with a branch for each version of the given packages, and uses it as the
+// index repository for the rest of the test. Packages are given as 'name @ version', mapped to their requirements,
+// and each provides a session of the same name.
+func testIndex(t *testing.T, packages map[string][]string) string {
+	repo := t.TempDir()
+	gitCommand(t, repo, "init", "-q")
+	addIndexVersions(t, repo, packages)
+
+	// the index repository is cloned into the local cache within the home directory
+	t.Setenv("HOME", t.TempDir())
+
+	url, level := internal.IndexRepositoryUrl, internal.LogLevel
+	t.Cleanup(func() { internal.IndexRepositoryUrl, internal.LogLevel = url, level })
+	internal.IndexRepositoryUrl, internal.LogLevel = repo, internal.LogLvlQuiet
+
+	return repo
+}
+
+// addIndexVersions adds a branch to the given index repository for each version of the given packages, see
+// testIndex.
+func addIndexVersions(t *testing.T, repo string, packages map[string][]string) {
+	byVersion := make(map[string][]config.Requirement)
+	for key := range packages {
+		req := mustRequirement(t, key)
+		byVersion[req.Constraint.String()] = append(byVersion[req.Constraint.String()], req)
+	}
+
+	for _, version := range slices.Sorted(maps.Keys(byVersion)) {
+		gitCommand(t, repo, "checkout", "-q", "--orphan", version)
+		gitCommand(t, repo, "rm", "-rfq", "--ignore-unmatch", ".")
+
+		for _, pkg := range byVersion[version] {
+			// each package provides a single session of the same name
+			cfg := &config.ProofmanConfig{
+				Project:  config.Project{Name: pkg.Name, Version: version},
+				Sessions: []config.Session{{Name: pkg.Name}},
+			}
+			for _, raw := range packages[pkg.Name+" @ "+version] {
+				cfg.Project.Requires = append(cfg.Project.Requires, mustRequirement(t, raw))
+			}
+
+			directory := filepath.Join(repo, "thys", pkg.Name)
+			if err := os.MkdirAll(directory, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := config.ToFile(directory, cfg); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		gitCommand(t, repo, "add", "-A")
+		gitCommand(t, repo, "commit", "-qm", version)
+	}
+}
+
+// testProject creates a project with the given requirements and a virtual environment, and makes it the working
+// directory for the rest of the test.
+func testProject(t *testing.T, requires ...string) string {
+	directory := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(directory, internal.VenvDirName, internal.DepsDirName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Project.Name, cfg.Project.Version = "Project", "2024-01-01"
+	for _, raw := range requires {
+		cfg.Project.Requires = append(cfg.Project.Requires, mustRequirement(t, raw))
+	}
+	if err := config.ToFile(directory, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(pwd) })
+	if err = os.Chdir(directory); err != nil {
+		t.Fatal(err)
+	}
+
+	return directory
+}
+
+// runCommand runs the given command as if it was invoked from the command line.
+func runCommand(command *cli.Command, args ...string) error {
+	app := &cli.App{Name: "proofman", Commands: []*cli.Command{command}}
+	return app.Run(append([]string{"proofman", command.Name}, args...))
+}
+
+// lockedVersions returns the version of each package within the lockfile of the given project.
+func lockedVersions(t *testing.T, directory string) map[string]string {
+	lock, err := lockfile.FromFile(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions := make(map[string]string)
+	if lock != nil {
+		for _, pkg := range lock.Packages {
+			versions[pkg.Name] = pkg.Version.String()
+		}
+	}
+
+	return versions
+}
+
+// installedPackages returns the names of the packages installed into the virtual environment of the given project.
+func installedPackages(t *testing.T, directory string) []string {
+	installed, err := venv.Installed(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return slices.Sorted(maps.Keys(installed))
+}
+
+// requirementStrings returns each of the given requirements formatted as a string.
+func requirementStrings(requires []config.Requirement) []string {
+	formatted := make([]string, 0, len(requires))
+	for _, req := range requires {
+		formatted = append(formatted, req.String())
+	}
+
+	return formatted
+}
+
+// captureStdout returns everything written to stdout by the given function, alongside the error it returned.
+func captureStdout(t *testing.T, f func() error) (string, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	err = f()
+	_ = writer.Close()
+
+	output, readErr := io.ReadAll(reader)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	return string(output), err
+}
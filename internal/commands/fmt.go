@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/parser"
+	"github.com/urfave/cli/v2"
+	"os"
+)
+
+// formatRootFile formats the ROOT file at the given path. The file is only rewritten if check is false. Returns
+// whether the file was not already formatted.
+func formatRootFile(path string, check bool) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read '%s' - %s", path, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read '%s' - %s", path, err)
+	}
+
+	formatted, err := parser.FormatRootFile(bytes.NewReader(content))
+	if err != nil {
+		var diagnostic *parser.Diagnostic
+		if errors.As(err, &diagnostic) {
+			diagnostic.File = path
+		}
+
+		return false, fmt.Errorf("failed to parse '%s' - %s", path, err)
+	}
+
+	if formatted == string(content) {
+		logging.Verbose("%s is already formatted", path)
+		return false, nil
+	}
+	if check {
+		return true, nil
+	}
+
+	if err = os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to write '%s' - %s", path, err)
+	}
+
+	return true, nil
+}
+
+func format(cCtx *cli.Context) error {
+	paths := cCtx.Args().Slice()
+	if len(paths) == 0 {
+		paths = []string{"ROOT"}
+	}
+
+	check := cCtx.Bool("check")
+
+	unformatted := 0
+	for _, path := range paths {
+		changed, err := formatRootFile(path, check)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+
+		unformatted++
+		if check {
+			logging.Quiet("%s is not formatted", path)
+		} else {
+			logging.Unquiet("formatted %s", path)
+		}
+	}
+
+	if check && unformatted > 0 {
+		return fmt.Errorf("%d file(s) are not formatted - run 'proofman fmt' to format them", unformatted)
+	}
+	if unformatted == 0 {
+		logging.Unquiet("all %d file(s) are already formatted", len(paths))
+	}
+
+	return nil
+}
+
+var FmtCommand = &cli.Command{
+	Name:      "fmt",
+	Usage:     "Rewrites ROOT files into the canonical layout",
+	ArgsUsage: "[<ROOT file>...]",
+	Action:    format,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "check",
+			Usage: "Exit with a non-zero status if any file is not formatted, instead of rewriting it",
+		},
+	},
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"errors"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/index"
+	"github.com/tandemdude/proofman/pkg/lockfile"
+	"github.com/tandemdude/proofman/pkg/resolver"
+	"github.com/tandemdude/proofman/pkg/venv"
+	"maps"
+	"slices"
+)
+
+// packageNode is a single package within the dependency graph of a project.
+type packageNode struct {
+	Version  config.Version
+	Requires []string
+}
+
+// projectGraph builds the transitive package graph of the project. The graph is read from the lockfile when it
+// is up-to-date with the project requirements, otherwise the requirements are resolved against the index repository.
+// The returned slice contains the names of the direct requirements of the project.
+func projectGraph(projectDirectory string, cfg *config.ProofmanConfig) ([]string, map[string]*packageNode, error) {
+	roots := make([]string, 0, len(cfg.Project.Requires))
+	for _, req := range cfg.Project.Requires {
+		roots = append(roots, req.Name)
+	}
+
+	graph := make(map[string]*packageNode)
+
+	lock, err := lockfile.FromFile(projectDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if lock != nil && lock.Verify(cfg.Project.Requires) == nil {
+		logging.Verbose("reading dependency graph from %s", lockfile.FileName)
+
+		for _, pkg := range lock.Packages {
+			node := &packageNode{Version: pkg.Version, Requires: make([]string, 0, len(pkg.Requires))}
+			for _, req := range pkg.Requires {
+				node.Requires = append(node.Requires, req.Name)
+			}
+
+			graph[pkg.Name] = node
+		}
+
+		return roots, graph, nil
+	}
+
+	logging.Verbose("resolving dependency graph from the index repository")
+	repo, err := index.Open(internal.IndexRepositoryUrl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolved, err := resolver.Resolve(repo, cfg.Project.Requires)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(resolved)) {
+		manifest, err := repo.Manifest(resolved[name], name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		node := &packageNode{Version: resolved[name], Requires: make([]string, 0, len(manifest.Project.Requires))}
+		for _, req := range manifest.Project.Requires {
+			node.Requires = append(node.Requires, req.Name)
+		}
+
+		graph[name] = node
+	}
+
+	return roots, graph, nil
+}
+
+// graphManifests returns the manifest of every package in the graph. Manifests are read from the virtual environment
+// where the installed version matches the graph, and from the index repository otherwise.
+func graphManifests(projectDirectory string, graph map[string]*packageNode) (map[string]*config.ProofmanConfig, error) {
+	installed, err := venv.Installed(projectDirectory)
+	if err != nil && !errors.Is(err, venv.ErrNoVirtualEnvironment) {
+		return nil, err
+	}
+
+	var repo *index.Repository
+	manifests := make(map[string]*config.ProofmanConfig, len(graph))
+	for _, name := range slices.Sorted(maps.Keys(graph)) {
+		node := graph[name]
+
+		if manifest, ok := installed[name]; ok && manifest.Project.Version == node.Version.String() {
+			manifests[name] = manifest
+			continue
+		}
+
+		if repo == nil {
+			logging.Verbose("%s @ %s is not installed - reading manifests from the index repository", name, node.Version)
+			if repo, err = index.Open(internal.IndexRepositoryUrl); err != nil {
+				return nil, err
+			}
+		}
+
+		manifest, err := repo.Manifest(node.Version, name)
+		if err != nil {
+			return nil, err
+		}
+		manifests[name] = manifest
+	}
+
+	return manifests, nil
+}
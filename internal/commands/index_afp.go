@@ -4,18 +4,28 @@ import (
 	"github.com/tandemdude/proofman/internal/logging"
 	"github.com/tandemdude/proofman/pkg/indexer"
 	"github.com/urfave/cli/v2"
+	"runtime"
 )
 
-func index(cCtx *cli.Context) error {
+func indexAfp(cCtx *cli.Context) error {
 	afpPath := cCtx.String("afp-path")
 	repoUrl := cCtx.String("repo-url")
 
-	if afpPath == "" || repoUrl == "" {
-		logging.Quiet("afp-path and repo-url are required flags")
+	dryRun, outputDir := cCtx.Bool("dry-run"), cCtx.String("output-dir")
+
+	// the index repository is only needed when the index is going to be pushed to it
+	if afpPath == "" || (repoUrl == "" && !dryRun && outputDir == "") {
+		logging.Quiet("repo-url is required unless --dry-run or --output-dir is used")
 		return nil
 	}
 
-	idx, err := indexer.New(afpPath, repoUrl, cCtx.String("use-version"))
+	idx, err := indexer.New(afpPath, repoUrl, cCtx.String("use-version"), indexer.Options{
+		Jobs:            cCtx.Int("jobs"),
+		DryRun:          dryRun,
+		OutputDirectory: outputDir,
+		Full:            cCtx.Bool("full"),
+		AllowCycles:     cCtx.Bool("allow-cycles"),
+	})
 	if err != nil {
 		return err
 	}
@@ -31,7 +41,7 @@ func index(cCtx *cli.Context) error {
 var IndexAfpCommand = &cli.Command{
 	Name:   "index-afp",
 	Usage:  "Indexes the AFP and uploads it to the index Git repository",
-	Action: index,
+	Action: indexAfp,
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:     "afp-path",
@@ -39,13 +49,35 @@ var IndexAfpCommand = &cli.Command{
 			Required: true,
 		},
 		&cli.StringFlag{
-			Name:     "repo-url",
-			Usage:    "The `INDEX_REPO_URL` to upload the indexed packages to",
-			Required: true,
+			Name: "repo-url",
+			Usage: "The `INDEX_REPO_URL` to upload the indexed packages to. Not required with --dry-run or --output-dir, " +
+				"where it is only read to compare against the previous indexed version",
 		},
 		&cli.StringFlag{
 			Name:  "use-version",
 			Usage: "The `VERSION` of the AFP that is being indexed. If unspecified it will be inferred from the /etc/version file",
 		},
+		&cli.IntFlag{
+			Name:  "jobs",
+			Usage: "Parse up to `JOBS` packages concurrently",
+			Value: runtime.NumCPU(),
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print the manifests that would be written without modifying the AFP directory or the index repository",
+		},
+		&cli.BoolFlag{
+			Name:  "allow-cycles",
+			Usage: "Report cyclic dependencies between packages as warnings instead of failing",
+		},
+		&cli.BoolFlag{
+			Name: "full",
+			Usage: "Parse every package again, instead of only those that changed since the previous indexed version. " +
+				"Always enabled with --dry-run or --output-dir unless --repo-url is also given",
+		},
+		&cli.StringFlag{
+			Name:  "output-dir",
+			Usage: "Write the index to the local `DIRECTORY` instead of committing and pushing it to the index repository",
+		},
 	},
 }
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/urfave/cli/v2"
+	"slices"
+	"strings"
+)
+
+// joinOrNone joins the values for display, returning 'none' if there are no values.
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
+
+func info(cCtx *cli.Context) error {
+	if cCtx.NArg() != 1 {
+		return fmt.Errorf("exactly one package is required")
+	}
+	name := cCtx.Args().First()
+
+	repo, err := openCachedIndex()
+	if err != nil {
+		return err
+	}
+
+	versions, err := repo.PackageVersions(name)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("package %s is not present in the index repository", name)
+	}
+
+	version := versions[len(versions)-1]
+	if raw := cCtx.String("use-version"); raw != "" {
+		if version, err = config.ParseVersion(raw); err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(versions, func(v config.Version) bool { return v.Compare(version) == 0 }) {
+			return fmt.Errorf("package %s is not present in version %s of the index repository", name, version)
+		}
+	}
+
+	manifest, err := repo.Manifest(version, name)
+	if err != nil {
+		return err
+	}
+
+	entries, err := repo.Entries(version)
+	if err != nil {
+		return err
+	}
+
+	requiredBy := make([]string, 0)
+	for _, entry := range entries {
+		if slices.ContainsFunc(entry.Requires, func(req config.Requirement) bool { return req.Name == name }) {
+			requiredBy = append(requiredBy, entry.Name)
+		}
+	}
+	slices.Sort(requiredBy)
+
+	available := make([]string, 0, len(versions))
+	for _, v := range versions {
+		available = append(available, v.String())
+	}
+
+	requires := make([]string, 0, len(manifest.Project.Requires))
+	for _, req := range manifest.Project.Requires {
+		requires = append(requires, req.String())
+	}
+
+	project := manifest.Project
+	logging.Quiet("%s %s", project.Name, project.Version)
+	if project.Title != "" {
+		logging.Quiet("%s", project.Title)
+	} else {
+		logging.Quiet("%s", project.Description)
+	}
+	logging.Quiet("")
+
+	// logged messages are trimmed, so the labels are aligned using arguments
+	field := func(label, value string) {
+		logging.Quiet("%s%s", fmt.Sprintf("%-19s", label+":"), value)
+	}
+	if project.Homepage != "" {
+		field("homepage", project.Homepage)
+	}
+	if len(project.Authors) > 0 {
+		field("authors", strings.Join(project.Authors, ", "))
+	}
+	if project.License != "" {
+		field("license", project.License)
+	}
+	if len(project.Topics) > 0 {
+		field("topics", strings.Join(project.Topics, ", "))
+	}
+	field("versions", strings.Join(available, ", "))
+	field("provides sessions", joinOrNone(manifest.ProvidedSessions()))
+	field("requires sessions", joinOrNone(manifest.RequiredSessions()))
+	field("requires packages", joinOrNone(requires))
+	field("required by", joinOrNone(requiredBy))
+
+	if project.Abstract != "" {
+		logging.Quiet("")
+		logging.Quiet("%s", project.Abstract)
+	}
+
+	return nil
+}
+
+var InfoCommand = &cli.Command{
+	Name:      "info",
+	Usage:     "Prints the details of a package within the cached index repository",
+	ArgsUsage: "<package>",
+	Action:    info,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "use-version",
+			Usage: "The indexed `VERSION` of the package to describe. If unspecified the newest version containing the package is used",
+		},
+	},
+}
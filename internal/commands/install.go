@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/checksum"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/index"
+	"github.com/tandemdude/proofman/pkg/lockfile"
+	"github.com/tandemdude/proofman/pkg/resolver"
+	"github.com/tandemdude/proofman/pkg/venv"
+	"github.com/urfave/cli/v2"
+	"maps"
+	"os"
+	"slices"
+)
+
+// lockPackages pins each of the given packages to the commit of the index repository that they will be installed
+// from, alongside a hash of the package contents at that commit.
+func lockPackages(repo *index.Repository, packages map[string]config.Version) ([]lockfile.Package, error) {
+	locked := make([]lockfile.Package, 0, len(packages))
+
+	// group the packages by version so each index branch only needs to be checked out once
+	byVersion := make(map[config.Version][]string)
+	for name, version := range packages {
+		byVersion[version] = append(byVersion[version], name)
+	}
+
+	for _, version := range slices.SortedFunc(maps.Keys(byVersion), config.Version.Compare) {
+		commit, err := repo.Commit(version)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = repo.CheckoutCommit(commit); err != nil {
+			return nil, err
+		}
+
+		for _, name := range byVersion[version] {
+			manifest, err := repo.Manifest(version, name)
+			if err != nil {
+				return nil, err
+			}
+
+			hash, err := checksum.Directory(repo.PackagePath(name))
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash %s - %s", name, err)
+			}
+
+			locked = append(locked, lockfile.Package{
+				Name:     name,
+				Version:  version,
+				Commit:   commit,
+				Hash:     hash,
+				Requires: manifest.Project.Requires,
+			})
+		}
+	}
+
+	return locked, nil
+}
+
+// installPackages copies each of the given packages out of the index repository and into the virtual environment
+// of the project, verifying that the package contents match the locked hash. Any installed packages that are not
+// within the given packages are removed so that the virtual environment matches the lockfile exactly.
+func installPackages(projectDirectory string, repo *index.Repository, packages []lockfile.Package) error {
+	byCommit := make(map[string][]lockfile.Package)
+	for _, pkg := range packages {
+		byCommit[pkg.Commit] = append(byCommit[pkg.Commit], pkg)
+	}
+
+	for _, commit := range slices.Sorted(maps.Keys(byCommit)) {
+		if err := repo.CheckoutCommit(commit); err != nil {
+			return err
+		}
+
+		for _, pkg := range byCommit[commit] {
+			logging.Unquiet("installing %s @ %s", pkg.Name, pkg.Version)
+
+			hash, err := checksum.Directory(repo.PackagePath(pkg.Name))
+			if err != nil {
+				return fmt.Errorf("failed to hash %s - %s", pkg.Name, err)
+			}
+			if hash != pkg.Hash {
+				return fmt.Errorf("hash mismatch for %s - locked %s but index contains %s", pkg.Name, pkg.Hash, hash)
+			}
+
+			if err = venv.Install(projectDirectory, pkg.Name, repo.PackagePath(pkg.Name)); err != nil {
+				return fmt.Errorf("failed to install %s - %s", pkg.Name, err)
+			}
+		}
+	}
+
+	installed, err := venv.Installed(projectDirectory)
+	if err != nil {
+		return err
+	}
+	for name := range installed {
+		if slices.ContainsFunc(packages, func(pkg lockfile.Package) bool { return pkg.Name == name }) {
+			continue
+		}
+
+		logging.Unquiet("removing %s", name)
+		if err = venv.Remove(projectDirectory, name); err != nil {
+			return fmt.Errorf("failed to remove %s - %s", name, err)
+		}
+	}
+
+	return venv.WriteRoots(projectDirectory)
+}
+
+// printInstallPlan prints the install and build plan for the given packages, without installing them.
+func printInstallPlan(projectDirectory string, repo *index.Repository, packages []lockfile.Package) error {
+	manifests := make(map[string]*config.ProofmanConfig, len(packages))
+	for _, pkg := range packages {
+		manifest, err := repo.Manifest(pkg.Version, pkg.Name)
+		if err != nil {
+			return err
+		}
+
+		manifests[pkg.Name] = manifest
+	}
+
+	p, err := projectPlan(projectDirectory, manifests)
+	if err != nil {
+		return err
+	}
+
+	printPlan(p)
+	return nil
+}
+
+// lockProject resolves the given project requirements against the index repository and returns the
+// resulting lockfile. The preferred versions, which may be nil, are chosen wherever they satisfy every requirement,
+// see resolver.ResolvePreferring.
+func lockProject(
+	repo *index.Repository,
+	requires []config.Requirement,
+	preferred map[string]config.Version,
+) (*lockfile.Lockfile, error) {
+	for _, req := range requires {
+		if req.Source != "" {
+			return nil, fmt.Errorf("cannot lock %s - %w", req, config.ErrUnsupportedSource)
+		}
+	}
+
+	resolved, err := resolver.ResolvePreferring(repo, requires, preferred)
+	if err != nil {
+		return nil, err
+	}
+
+	packages, err := lockPackages(repo, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lockfile.Lockfile{Requires: requires, Packages: packages}, nil
+}
+
+func install(cCtx *cli.Context) error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	repo, err := index.Open(internal.IndexRepositoryUrl)
+	if err != nil {
+		return err
+	}
+
+	lock, err := lockfile.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", lockfile.FileName, err)
+	}
+
+	// with no packages specified, install exactly what the lockfile describes
+	if cCtx.NArg() == 0 && lock != nil && !cCtx.Bool("ignore-lock") {
+		if err = lock.Verify(cfg.Project.Requires); err != nil {
+			return err
+		}
+
+		if cCtx.Bool("plan") {
+			return printInstallPlan(pwd, repo, lock.Packages)
+		}
+
+		if err = installPackages(pwd, repo, lock.Packages); err != nil {
+			return err
+		}
+
+		logging.Unquiet("installed %d locked package(s) successfully", len(lock.Packages))
+		return nil
+	}
+
+	// record the requested packages as direct requirements of the project, replacing any
+	// requirements that previously referenced the same package
+	requested := make(map[string]config.Requirement)
+	for _, arg := range cCtx.Args().Slice() {
+		req, err := config.ParseRequirement(arg)
+		if err != nil {
+			return err
+		}
+
+		// pin packages requested without a constraint to their newest version
+		if req.Constraint.IsAny() {
+			versions, err := repo.PackageVersions(req.Name)
+			if err != nil {
+				return err
+			}
+			if len(versions) == 0 {
+				return fmt.Errorf("package %s is not present in the index repository", req.Name)
+			}
+
+			req.Constraint = config.ExactConstraint(versions[len(versions)-1])
+		}
+
+		requested[req.Name] = req
+	}
+
+	requires := make([]config.Requirement, 0, len(cfg.Project.Requires)+len(requested))
+	for _, req := range cfg.Project.Requires {
+		if _, ok := requested[req.Name]; !ok {
+			requires = append(requires, req)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(requested)) {
+		requires = append(requires, requested[name])
+	}
+
+	// the packages that were not requested keep their locked versions wherever possible, so that installing a
+	// package does not update unrelated ones
+	preferred := make(map[string]config.Version)
+	if lock != nil && !cCtx.Bool("ignore-lock") {
+		for _, pkg := range lock.Packages {
+			if _, ok := requested[pkg.Name]; !ok {
+				preferred[pkg.Name] = pkg.Version
+			}
+		}
+	}
+
+	lock, err = lockProject(repo, requires, preferred)
+	if err != nil {
+		return err
+	}
+
+	if cCtx.Bool("plan") {
+		return printInstallPlan(pwd, repo, lock.Packages)
+	}
+
+	if err = installPackages(pwd, repo, lock.Packages); err != nil {
+		return err
+	}
+
+	cfg.Project.Requires = requires
+	if err = config.ToFile(pwd, cfg); err != nil {
+		return fmt.Errorf("failed to update '%s' - %s", internal.ConfigFileName, err)
+	}
+	if err = lockfile.ToFile(pwd, lock); err != nil {
+		return fmt.Errorf("failed to update '%s' - %s", lockfile.FileName, err)
+	}
+
+	logging.Unquiet("installed %d package(s) successfully", len(lock.Packages))
+
+	return nil
+}
+
+var InstallCommand = &cli.Command{
+	Name:      "install",
+	Usage:     "Installs packages and their dependencies from the index repository into the virtual environment",
+	ArgsUsage: "[<package>[@version]...]",
+	Action:    install,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "ignore-lock",
+			Usage: "Resolve the project requirements again, ignoring the versions pinned by the lockfile",
+		},
+		&cli.BoolFlag{
+			Name:  "plan",
+			Usage: "Print the install and build plan without installing anything or modifying any files",
+		},
+	},
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/lockfile"
+	"github.com/tandemdude/proofman/pkg/venv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	assert := asrt.New(t)
+
+	testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {"B"},
+		"A @ 2024-06-01": {"B"},
+		"B @ 2024-01-01": {},
+		"B @ 2024-06-01": {},
+		"C @ 2024-01-01": {},
+	})
+	directory := testProject(t)
+
+	// packages requested without a constraint are pinned to their newest version
+	assert.NoError(runCommand(InstallCommand, "A"))
+	assert.Equal([]string{"A", "B"}, installedPackages(t, directory))
+	assert.Equal(map[string]string{"A": "2024-06-01", "B": "2024-06-01"}, lockedVersions(t, directory))
+
+	cfg, err := config.FromFile(directory)
+	assert.NoError(err)
+	assert.Equal([]string{"A @ 2024-06-01"}, requirementStrings(cfg.Project.Requires))
+
+	// requesting a package that is already required replaces its requirement
+	assert.NoError(runCommand(InstallCommand, "C @ 2024-01-01", "A @ 2024-01-01"))
+	assert.Equal([]string{"A", "B", "C"}, installedPackages(t, directory))
+	assert.Equal(map[string]string{"A": "2024-01-01", "B": "2024-06-01", "C": "2024-01-01"}, lockedVersions(t, directory))
+
+	cfg, err = config.FromFile(directory)
+	assert.NoError(err)
+	assert.Equal([]string{"A @ 2024-01-01", "C @ 2024-01-01"}, requirementStrings(cfg.Project.Requires))
+
+	assert.ErrorContains(runCommand(InstallCommand, "Missing"), "package Missing is not present in the index repository")
+}
+
+func TestInstallFromLock(t *testing.T) {
+	assert := asrt.New(t)
+
+	testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {"B"},
+		"B @ 2024-01-01": {},
+	})
+	directory := testProject(t, "A @ 2024-01-01")
+	assert.NoError(runCommand(LockCommand))
+	assert.Empty(installedPackages(t, directory))
+
+	// the virtual environment is made to match the lockfile exactly
+	stray := filepath.Join(venv.DepsPath(directory), "Stray")
+	assert.NoError(os.MkdirAll(stray, 0o755))
+	assert.NoError(config.ToFile(stray, &config.ProofmanConfig{Project: config.Project{Name: "Stray", Version: "2024-01-01"}}))
+
+	assert.NoError(runCommand(InstallCommand))
+	assert.Equal([]string{"A", "B"}, installedPackages(t, directory))
+
+	// the lockfile must still describe the project requirements
+	cfg, err := config.FromFile(directory)
+	assert.NoError(err)
+	cfg.Project.Requires = []config.Requirement{mustRequirement(t, "B @ 2024-01-01")}
+	assert.NoError(config.ToFile(directory, cfg))
+	assert.ErrorIs(runCommand(InstallCommand), lockfile.ErrOutOfDate)
+}
+
+func TestInstallRejectsSources(t *testing.T) {
+	assert := asrt.New(t)
+
+	testIndex(t, map[string][]string{"A @ 2024-01-01": {}})
+	directory := testProject(t)
+
+	err := runCommand(InstallCommand, "A @ 2024-01-01 from https://example.com/index.git")
+	assert.ErrorIs(err, config.ErrUnsupportedSource)
+
+	// nothing is installed from the index repository in place of the requested source
+	_, err = os.Stat(filepath.Join(directory, lockfile.FileName))
+	assert.ErrorIs(err, os.ErrNotExist)
+	cfg, err := config.FromFile(directory)
+	assert.NoError(err)
+	assert.Empty(cfg.Project.Requires)
+
+	// sources already written to the project config are rejected when locking
+	testProject(t, "A @ 2024-01-01 from https://example.com/index.git")
+	assert.ErrorContains(runCommand(LockCommand), config.ErrUnsupportedSource.Error())
+	_, err = lockProject(nil, []config.Requirement{mustRequirement(t, "A from https://example.com/index.git")}, nil)
+	assert.ErrorIs(err, config.ErrUnsupportedSource)
+}
+
+func TestInstallKeepsLockedVersions(t *testing.T) {
+	assert := asrt.New(t)
+
+	repo := testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {"C"},
+		"B @ 2024-01-01": {},
+		"C @ 2024-01-01": {},
+	})
+	directory := testProject(t, "A @ >=2024-01-01")
+
+	assert.NoError(runCommand(LockCommand))
+	assert.Equal(map[string]string{"A": "2024-01-01", "C": "2024-01-01"}, lockedVersions(t, directory))
+
+	// installing an unrelated package after newer versions are indexed leaves the locked packages at their versions
+	addIndexVersions(t, repo, map[string][]string{
+		"A @ 2024-06-01": {"C"},
+		"B @ 2024-06-01": {},
+		"C @ 2024-06-01": {},
+	})
+	assert.NoError(runCommand(InstallCommand, "B @ 2024-01-01"))
+	assert.Equal(map[string]string{"A": "2024-01-01", "B": "2024-01-01", "C": "2024-01-01"}, lockedVersions(t, directory))
+
+	// unless the lockfile is ignored
+	assert.NoError(runCommand(InstallCommand, "--ignore-lock", "B"))
+	assert.Equal(map[string]string{"A": "2024-06-01", "B": "2024-06-01", "C": "2024-06-01"}, lockedVersions(t, directory))
+}
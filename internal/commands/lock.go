@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/index"
+	"github.com/tandemdude/proofman/pkg/lockfile"
+	"github.com/urfave/cli/v2"
+	"os"
+)
+
+func lock(_ *cli.Context) error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	repo, err := index.Open(internal.IndexRepositoryUrl)
+	if err != nil {
+		return err
+	}
+
+	lock, err := lockProject(repo, cfg.Project.Requires, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = lockfile.ToFile(pwd, lock); err != nil {
+		return fmt.Errorf("failed to write '%s' - %s", lockfile.FileName, err)
+	}
+
+	logging.Unquiet("locked %d package(s) successfully", len(lock.Packages))
+
+	return nil
+}
+
+var LockCommand = &cli.Command{
+	Name:   "lock",
+	Usage:  "Resolves the project requirements and pins the exact package versions in the lockfile",
+	Action: lock,
+}
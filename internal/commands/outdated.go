@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/index"
+	"github.com/tandemdude/proofman/pkg/lockfile"
+	"github.com/urfave/cli/v2"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+func outdated(cCtx *cli.Context) error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	lock, err := lockfile.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", lockfile.FileName, err)
+	}
+	if lock == nil {
+		return fmt.Errorf("no '%s' found - run 'proofman lock' first", lockfile.FileName)
+	}
+
+	repo, err := index.Open(internal.IndexRepositoryUrl)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PACKAGE\tLOCKED\tLATEST\tNEWER VERSIONS")
+
+	count := 0
+	packages := slices.Clone(lock.Packages)
+	slices.SortFunc(packages, func(a, b lockfile.Package) int { return strings.Compare(a.Name, b.Name) })
+	for _, pkg := range packages {
+		versions, err := repo.PackageVersions(pkg.Name)
+		if err != nil {
+			return err
+		}
+
+		newer := make([]string, 0)
+		for _, v := range versions {
+			if v.Compare(pkg.Version) > 0 {
+				newer = append(newer, v.String())
+			}
+		}
+		if len(newer) == 0 {
+			continue
+		}
+
+		count++
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", pkg.Name, pkg.Version, newer[len(newer)-1], strings.Join(newer, ", "))
+	}
+
+	if count == 0 {
+		logging.Unquiet("all %d locked package(s) are up-to-date", len(lock.Packages))
+		return nil
+	}
+
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	logging.Quiet("%s", b.String())
+
+	return nil
+}
+
+var OutdatedCommand = &cli.Command{
+	Name:   "outdated",
+	Usage:  "Lists the locked packages that have newer versions available in the index repository",
+	Action: outdated,
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/isabelle"
+	"github.com/tandemdude/proofman/pkg/plan"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// projectSessions returns the sessions defined by the project ROOT file, in the same form as the sessions of
+// indexed packages. A project without a ROOT file defines no sessions.
+func projectSessions(projectDirectory string) ([]config.Session, error) {
+	if exists, _ := internal.PathExists(filepath.Join(projectDirectory, "ROOT")); !exists {
+		logging.Verbose("project has no ROOT file - no project sessions will be planned")
+		return nil, nil
+	}
+
+	parsed, err := parseProjectRoot(projectDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]config.Session, 0)
+	for _, chapterName := range parsed.ChapterOrder {
+		for _, session := range parsed.Chapters[chapterName].Sessions {
+			imports, err := isabelle.SessionImports(projectDirectory, session)
+			if err != nil {
+				return nil, err
+			}
+
+			imported := make([]string, 0, len(imports))
+			for _, imp := range imports {
+				if !slices.Contains(imported, imp.Session) {
+					imported = append(imported, imp.Session)
+				}
+			}
+
+			sessions = append(sessions, config.Session{
+				Name:     session.Name,
+				Parent:   session.SystemName,
+				Requires: session.Sessions,
+				Imports:  imported,
+			})
+		}
+	}
+
+	return sessions, nil
+}
+
+// projectPlan creates the install and build plan of the project for the given package manifests.
+func projectPlan(projectDirectory string, manifests map[string]*config.ProofmanConfig) (*plan.Plan, error) {
+	sessions, err := projectSessions(projectDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.New(manifests, sessions)
+}
+
+// printPlan writes the levels of the plan. Packages and sessions within the same level can be installed or
+// built in parallel.
+func printPlan(p *plan.Plan) {
+	logging.Quiet("install plan (%d level(s)):", len(p.Packages))
+	for i, level := range p.Packages {
+		logging.QuietIndented(1, "%d: %s", i+1, strings.Join(level, ", "))
+	}
+
+	logging.Quiet("build plan (%d level(s)):", len(p.Sessions))
+	for i, level := range p.Sessions {
+		names := make([]string, 0, len(level))
+		for _, session := range level {
+			if session.Package == "" {
+				names = append(names, session.Name+" (project)")
+			} else {
+				names = append(names, session.Name+" ("+session.Package+")")
+			}
+		}
+		logging.QuietIndented(1, "%d: %s", i+1, strings.Join(names, ", "))
+	}
+}
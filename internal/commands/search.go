@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/index"
+	"github.com/urfave/cli/v2"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxDescriptionLength is the number of characters of a package description shown in the search results table.
+const maxDescriptionLength = 60
+
+type searchResult struct {
+	Name        string         `json:"name"`
+	Version     config.Version `json:"version"`
+	Description string         `json:"description"`
+	Sessions    []string       `json:"sessions"`
+	Topics      []string       `json:"topics"`
+	Score       int            `json:"score"`
+}
+
+// openCachedIndex opens the local clone of the index repository without fetching, only cloning it if it
+// does not exist yet.
+func openCachedIndex() (*index.Repository, error) {
+	repo, err := index.OpenCached(internal.IndexRepositoryUrl)
+	if errors.Is(err, index.ErrNotCached) {
+		return index.Open(internal.IndexRepositoryUrl)
+	}
+
+	return repo, err
+}
+
+func truncate(s string, length int) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	return string(runes[:length-3]) + "..."
+}
+
+func search(cCtx *cli.Context) error {
+	if cCtx.NArg() == 0 {
+		return fmt.Errorf("a search query is required")
+	}
+	query := strings.Join(cCtx.Args().Slice(), " ")
+
+	repo, err := openCachedIndex()
+	if err != nil {
+		return err
+	}
+
+	var version config.Version
+	if raw := cCtx.String("use-version"); raw != "" {
+		version, err = config.ParseVersion(raw)
+	} else {
+		version, err = repo.Latest()
+	}
+	if err != nil {
+		return err
+	}
+
+	entries, err := repo.Entries(version)
+	if err != nil {
+		return err
+	}
+	matches := index.Search(entries, query)
+
+	if cCtx.Bool("json") {
+		results := make([]searchResult, 0, len(matches))
+		for _, m := range matches {
+			results = append(results, searchResult{
+				Name:        m.Name,
+				Version:     m.Version,
+				Description: m.Description,
+				Sessions:    append([]string{}, m.Sessions...),
+				Topics:      append([]string{}, m.Topics...),
+				Score:       m.Score,
+			})
+		}
+
+		marshalled, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		// printed directly so that the output remains valid JSON regardless of the log level
+		fmt.Println(string(marshalled))
+		return nil
+	}
+
+	if len(matches) == 0 {
+		logging.Quiet("no packages matching '%s' found in version %s", query, version)
+		return nil
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PACKAGE\tVERSION\tDESCRIPTION")
+	for _, m := range matches {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", m.Name, m.Version, truncate(m.Description, maxDescriptionLength))
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	logging.Quiet("%s", b.String())
+
+	return nil
+}
+
+var SearchCommand = &cli.Command{
+	Name:      "search",
+	Usage:     "Searches the cached index repository for packages by name, session, topic or description",
+	ArgsUsage: "<query>...",
+	Action:    search,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "Print the matching packages as JSON",
+		},
+		&cli.StringFlag{
+			Name:  "use-version",
+			Usage: "The indexed `VERSION` to search. If unspecified the newest indexed version is used",
+		},
+	},
+}
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/hashicorp/go-set/v3"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/index"
+	"github.com/tandemdude/proofman/pkg/isabelle"
+	"github.com/tandemdude/proofman/pkg/parser"
+	"github.com/tandemdude/proofman/pkg/parser/structure"
+	"github.com/urfave/cli/v2"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// parseProjectRoot parses the ROOT file of the project.
+func parseProjectRoot(projectDirectory string) (*structure.RootStructure, error) {
+	path := filepath.Join(projectDirectory, "ROOT")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ROOT file - %s", err)
+	}
+
+	parsed, err := parser.ParseNamedRootFile(path, bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ROOT file - %s", err)
+	}
+
+	return parsed, nil
+}
+
+// usedSessions parses the project ROOT file and returns every session referenced by the project - either as a
+// parent session, within a 'sessions' block, or as the qualifier of a theory import - mapped to a description of
+// where it was referenced. Sessions defined by the project itself are excluded.
+func usedSessions(projectDirectory string) (map[string]string, error) {
+	parsed, err := parseProjectRoot(projectDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]string)
+	use := func(session, reason string) {
+		if _, ok := used[session]; !ok {
+			used[session] = reason
+		}
+	}
+
+	local := set.New[string](0)
+	for _, chapterName := range parsed.ChapterOrder {
+		for _, session := range parsed.Chapters[chapterName].Sessions {
+			local.Insert(session.Name)
+
+			if session.SystemName != "" {
+				use(session.SystemName, fmt.Sprintf("session %s: ROOT parent session %s", session.Name, session.SystemName))
+			}
+			for _, s := range session.Sessions {
+				use(s, fmt.Sprintf("session %s: ROOT sessions entry %s", session.Name, s))
+			}
+
+			imports, err := isabelle.SessionImports(projectDirectory, session)
+			if err != nil {
+				return nil, err
+			}
+			for _, imp := range imports {
+				use(imp.Session, fmt.Sprintf("session %s: theory %s imports %s", session.Name, imp.Theory, imp.Import))
+			}
+		}
+	}
+
+	for session := range local.Items() {
+		delete(used, session)
+	}
+
+	return used, nil
+}
+
+func tidy(cCtx *cli.Context) error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	used, err := usedSessions(pwd)
+	if err != nil {
+		return err
+	}
+
+	repo, err := index.Open(internal.IndexRepositoryUrl)
+	if err != nil {
+		return err
+	}
+
+	var version config.Version
+	if raw := cCtx.String("use-version"); raw != "" {
+		version, err = config.ParseVersion(raw)
+	} else {
+		version, err = repo.Latest()
+	}
+	if err != nil {
+		return err
+	}
+
+	sessionPackages, err := repo.SessionPackages(version)
+	if err != nil {
+		return err
+	}
+
+	needed := set.New[string](0)
+	for _, session := range slices.Sorted(maps.Keys(used)) {
+		pkg, ok := sessionPackages[session]
+		if !ok {
+			logging.Verbose("session %s is not provided by any indexed package - assuming it is an Isabelle builtin session", session)
+			continue
+		}
+
+		logging.Verbose("session %s is provided by %s (%s)", session, pkg, used[session])
+		needed.Insert(pkg)
+	}
+
+	changed := false
+	requires := make([]config.Requirement, 0, needed.Size())
+	for _, req := range cfg.Project.Requires {
+		if !needed.Contains(req.Name) {
+			logging.Unquiet("- %s", req)
+			changed = true
+			continue
+		}
+
+		requires = append(requires, req)
+		needed.Remove(req.Name)
+	}
+	for _, pkg := range slices.Sorted(needed.Items()) {
+		req := config.Requirement{Name: pkg, Constraint: config.ExactConstraint(version)}
+		logging.Unquiet("+ %s", req)
+
+		requires = append(requires, req)
+		changed = true
+	}
+
+	if !changed {
+		logging.Unquiet("requirements are already tidy")
+		return nil
+	}
+
+	cfg.Project.Requires = requires
+	if err = config.ToFile(pwd, cfg); err != nil {
+		return fmt.Errorf("failed to update '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	logging.Unquiet("updated requirements - run 'proofman install --ignore-lock' to apply them")
+
+	return nil
+}
+
+var TidyCommand = &cli.Command{
+	Name:   "tidy",
+	Usage:  "Adds missing and removes unused requirements based on the sessions used by the project ROOT file",
+	Action: tidy,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "use-version",
+			Usage: "The indexed `VERSION` to add missing requirements from. If unspecified the newest indexed version is used",
+		},
+	},
+}
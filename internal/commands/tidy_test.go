@@ -0,0 +1,56 @@
+package commands
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/pkg/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTidy(t *testing.T) {
+	assert := asrt.New(t)
+
+	testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {},
+		"A @ 2024-06-01": {},
+		"B @ 2024-01-01": {},
+		"B @ 2024-06-01": {},
+		"C @ 2024-01-01": {},
+		"C @ 2024-06-01": {},
+		"X @ 2024-01-01": {},
+	})
+	directory := testProject(t, "B @ 2024-01-01", "X @ 2024-01-01")
+	writeProjectFiles(t, directory, map[string]string{
+		"ROOT":    "session Project = A +\n  sessions B\n  theories Foo\n",
+		"Foo.thy": "theory Foo imports \"C.Bar\" \"HOL.Main\" begin end",
+	})
+
+	// unused requirements are removed, and missing ones are added from the newest version
+	assert.NoError(runCommand(TidyCommand))
+	cfg, err := config.FromFile(directory)
+	assert.NoError(err)
+	assert.Equal([]string{"B @ 2024-01-01", "A @ 2024-06-01", "C @ 2024-06-01"}, requirementStrings(cfg.Project.Requires))
+
+	internal.LogLevel = internal.LogLvlUnquiet
+	output, err := captureStdout(t, func() error { return runCommand(TidyCommand) })
+	assert.NoError(err)
+	assert.Equal("requirements are already tidy\n", output)
+
+	// or from the given version
+	cfg.Project.Requires = nil
+	assert.NoError(config.ToFile(directory, cfg))
+	assert.NoError(runCommand(TidyCommand, "--use-version", "2024-01-01"))
+	cfg, err = config.FromFile(directory)
+	assert.NoError(err)
+	assert.Equal([]string{"A @ 2024-01-01", "B @ 2024-01-01", "C @ 2024-01-01"}, requirementStrings(cfg.Project.Requires))
+}
+
+func writeProjectFiles(t *testing.T, directory string, files map[string]string) {
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-set/v3"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/urfave/cli/v2"
+	"os"
+	"slices"
+)
+
+type treePrinter struct {
+	label    func(name string) string
+	children func(name string) []string
+	maxDepth int
+	shown    *set.Set[string]
+}
+
+// print writes the subtree rooted at name. Subtrees that have already been written are not expanded
+// again, and are instead marked with '(*)'. A subtree cut off by the maximum depth is not considered written,
+// so it is expanded wherever it next appears within the depth limit.
+func (t *treePrinter) print(name, prefix string, last bool, depth int) {
+	connector, childPrefix := "├── ", prefix+"│   "
+	if last {
+		connector, childPrefix = "└── ", prefix+"    "
+	}
+
+	if t.shown.Contains(name) {
+		logging.Quiet("%s%s%s (*)", prefix, connector, t.label(name))
+		return
+	}
+	logging.Quiet("%s%s%s", prefix, connector, t.label(name))
+
+	children := t.children(name)
+	if len(children) > 0 && !t.expands(depth+1) {
+		return
+	}
+
+	// recorded before the children are written, so that cycles are not expanded forever
+	t.shown.Insert(name)
+	t.printChildren(children, childPrefix, depth+1)
+}
+
+// expands returns whether nodes at the given depth are written.
+func (t *treePrinter) expands(depth int) bool {
+	return t.maxDepth < 0 || depth <= t.maxDepth
+}
+
+func (t *treePrinter) printChildren(children []string, prefix string, depth int) {
+	if !t.expands(depth) {
+		return
+	}
+
+	for i, child := range children {
+		t.print(child, prefix, i == len(children)-1, depth)
+	}
+}
+
+func tree(cCtx *cli.Context) error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	roots, graph, err := projectGraph(pwd, cfg)
+	if err != nil {
+		return err
+	}
+
+	if cCtx.Bool("plan") {
+		manifests, err := graphManifests(pwd, graph)
+		if err != nil {
+			return err
+		}
+
+		p, err := projectPlan(pwd, manifests)
+		if err != nil {
+			return err
+		}
+
+		printPlan(p)
+		return nil
+	}
+
+	// the project itself is represented by the empty name, so it cannot clash with any package
+	const project = ""
+	label := func(name string) string {
+		if name == project {
+			return cfg.Project.Name + " " + cfg.Project.Version
+		}
+		if node, ok := graph[name]; ok {
+			return name + " " + node.Version.String()
+		}
+		return name + " (missing)"
+	}
+
+	edges := map[string][]string{project: roots}
+	for name, node := range graph {
+		edges[name] = node.Requires
+	}
+
+	maxDepth := -1
+	if cCtx.IsSet("depth") {
+		maxDepth = cCtx.Int("depth")
+	}
+
+	printer := &treePrinter{label: label, maxDepth: maxDepth, shown: set.New[string](len(graph) + 1)}
+
+	root := project
+	if inverted := cCtx.String("invert"); inverted != "" {
+		if _, ok := graph[inverted]; !ok {
+			return fmt.Errorf("package %s is not a dependency of the project", inverted)
+		}
+
+		reversed := make(map[string][]string)
+		for name, requires := range edges {
+			for _, req := range requires {
+				reversed[req] = append(reversed[req], name)
+			}
+		}
+		edges, root = reversed, inverted
+	}
+
+	printer.children = func(name string) []string {
+		children := slices.Clone(edges[name])
+		slices.Sort(children)
+		return children
+	}
+
+	printer.shown.Insert(root)
+	logging.Quiet("%s", label(root))
+	printer.printChildren(printer.children(root), "", 1)
+
+	return nil
+}
+
+var TreeCommand = &cli.Command{
+	Name:   "tree",
+	Usage:  "Prints the dependency graph of the current project",
+	Action: tree,
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "depth",
+			Usage: "The maximum `DEPTH` of dependencies to display",
+		},
+		&cli.BoolFlag{
+			Name:  "plan",
+			Usage: "Print the install and build plan of the project instead of the dependency graph",
+		},
+		&cli.StringFlag{
+			Name:  "invert",
+			Usage: "Show the packages that depend on `PACKAGE`, instead of the packages the project depends on",
+		},
+	},
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {"C", "D"},
+		"B @ 2024-01-01": {"C"},
+		"C @ 2024-01-01": {"D"},
+		"D @ 2024-01-01": {},
+	})
+	testProject(t, "A @ 2024-01-01", "B @ 2024-01-01")
+	if err := runCommand(LockCommand); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name: "repeated packages are marked",
+			expected: []string{
+				"Project 2024-01-01",
+				"├── A 2024-01-01",
+				"│   ├── C 2024-01-01",
+				"│   │   └── D 2024-01-01",
+				"│   └── D 2024-01-01 (*)",
+				"└── B 2024-01-01",
+				"    └── C 2024-01-01 (*)",
+			},
+		},
+		{
+			name: "depth",
+			args: []string{"--depth", "1"},
+			expected: []string{
+				"Project 2024-01-01",
+				"├── A 2024-01-01",
+				"└── B 2024-01-01",
+			},
+		},
+		{
+			// C is cut off by the depth beneath A, so is not marked where it appears again
+			name: "packages cut off by the depth are not marked",
+			args: []string{"--depth", "2"},
+			expected: []string{
+				"Project 2024-01-01",
+				"├── A 2024-01-01",
+				"│   ├── C 2024-01-01",
+				"│   └── D 2024-01-01",
+				"└── B 2024-01-01",
+				"    └── C 2024-01-01",
+			},
+		},
+		{
+			name: "inverted",
+			args: []string{"--invert", "D"},
+			expected: []string{
+				"D 2024-01-01",
+				"├── A 2024-01-01",
+				"│   └── Project 2024-01-01",
+				"└── C 2024-01-01",
+				"    ├── A 2024-01-01 (*)",
+				"    └── B 2024-01-01",
+				"        └── Project 2024-01-01 (*)",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := asrt.New(t)
+
+			output, err := captureStdout(t, func() error { return runCommand(TreeCommand, test.args...) })
+			assert.NoError(err)
+			assert.Equal(strings.Join(test.expected, "\n")+"\n", output)
+		})
+	}
+
+	assert := asrt.New(t)
+	assert.EqualError(runCommand(TreeCommand, "--invert", "Missing"), "package Missing is not a dependency of the project")
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-set/v3"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/lockfile"
+	"github.com/tandemdude/proofman/pkg/venv"
+	"github.com/urfave/cli/v2"
+	"os"
+	"slices"
+	"strings"
+)
+
+// requiredPackages walks the requirements of the installed packages, starting from the given direct
+// requirements, and returns the names of every package that is still needed by the project.
+func requiredPackages(installed map[string]*config.ProofmanConfig, requires []config.Requirement) *set.Set[string] {
+	needed := set.New[string](len(installed))
+
+	queue := make([]string, 0, len(requires))
+	for _, req := range requires {
+		queue = append(queue, req.Name)
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		if !needed.Insert(name) {
+			continue
+		}
+
+		manifest, ok := installed[name]
+		if !ok {
+			logging.Verbose("package %s is required but not installed", name)
+			continue
+		}
+
+		for _, req := range manifest.Project.Requires {
+			queue = append(queue, req.Name)
+		}
+	}
+
+	return needed
+}
+
+func uninstall(cCtx *cli.Context) error {
+	if cCtx.NArg() == 0 {
+		return errors.New("at least one package is required")
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	toRemove := set.From(cCtx.Args().Slice())
+
+	requires := make([]config.Requirement, 0, len(cfg.Project.Requires))
+	for _, req := range cfg.Project.Requires {
+		if toRemove.Contains(req.Name) {
+			toRemove.Remove(req.Name)
+			continue
+		}
+
+		requires = append(requires, req)
+	}
+
+	if !toRemove.Empty() {
+		return fmt.Errorf("not direct requirements of the project: %s", strings.Join(slices.Sorted(toRemove.Items()), ", "))
+	}
+	cfg.Project.Requires = requires
+
+	installed, err := venv.Installed(pwd)
+	if err != nil {
+		return err
+	}
+
+	// any installed package that can no longer be reached from the remaining requirements is an orphan
+	needed := requiredPackages(installed, requires)
+	removed := 0
+	for name := range installed {
+		if needed.Contains(name) {
+			continue
+		}
+
+		logging.Unquiet("removing %s", name)
+		if err = venv.Remove(pwd, name); err != nil {
+			return fmt.Errorf("failed to remove %s - %s", name, err)
+		}
+		removed++
+	}
+
+	if err = venv.WriteRoots(pwd); err != nil {
+		return err
+	}
+
+	if err = config.ToFile(pwd, cfg); err != nil {
+		return fmt.Errorf("failed to update '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	// the remaining locked packages are still valid, so the lockfile only needs the removed packages dropping
+	lock, err := lockfile.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", lockfile.FileName, err)
+	}
+	if lock != nil {
+		lock.Requires = requires
+		lock.Packages = slices.DeleteFunc(lock.Packages, func(pkg lockfile.Package) bool {
+			return !needed.Contains(pkg.Name)
+		})
+
+		if err = lockfile.ToFile(pwd, lock); err != nil {
+			return fmt.Errorf("failed to update '%s' - %s", lockfile.FileName, err)
+		}
+	}
+
+	logging.Unquiet("removed %d package(s) successfully", removed)
+
+	return nil
+}
+
+var UninstallCommand = &cli.Command{
+	Name:      "uninstall",
+	Usage:     "Removes packages, and any dependencies no longer required, from the virtual environment",
+	ArgsUsage: "<package>...",
+	Action:    uninstall,
+}
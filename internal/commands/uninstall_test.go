@@ -0,0 +1,51 @@
+package commands
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/pkg/config"
+	"testing"
+)
+
+func TestUninstall(t *testing.T) {
+	assert := asrt.New(t)
+
+	testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {"B"},
+		"B @ 2024-01-01": {},
+		"C @ 2024-01-01": {"B"},
+	})
+	directory := testProject(t)
+	assert.NoError(runCommand(InstallCommand, "A", "C"))
+
+	// dependencies that are still required by another package are kept
+	assert.NoError(runCommand(UninstallCommand, "A"))
+	assert.Equal([]string{"B", "C"}, installedPackages(t, directory))
+	assert.Equal(map[string]string{"B": "2024-01-01", "C": "2024-01-01"}, lockedVersions(t, directory))
+
+	cfg, err := config.FromFile(directory)
+	assert.NoError(err)
+	assert.Equal([]string{"C @ 2024-01-01"}, requirementStrings(cfg.Project.Requires))
+
+	// orphaned dependencies are removed alongside the last package requiring them
+	assert.NoError(runCommand(UninstallCommand, "C"))
+	assert.Empty(installedPackages(t, directory))
+	assert.Empty(lockedVersions(t, directory))
+}
+
+func TestUninstallRejectsIndirectPackages(t *testing.T) {
+	assert := asrt.New(t)
+
+	testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {"B"},
+		"B @ 2024-01-01": {},
+	})
+	directory := testProject(t)
+	assert.NoError(runCommand(InstallCommand, "A"))
+
+	assert.EqualError(runCommand(UninstallCommand), "at least one package is required")
+	assert.EqualError(runCommand(UninstallCommand, "B", "Missing"), "not direct requirements of the project: B, Missing")
+
+	// nothing is removed when any of the packages cannot be uninstalled
+	assert.Error(runCommand(UninstallCommand, "A", "B"))
+	assert.Equal([]string{"A", "B"}, installedPackages(t, directory))
+}
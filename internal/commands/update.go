@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/index"
+	"github.com/tandemdude/proofman/pkg/lockfile"
+	"github.com/tandemdude/proofman/pkg/resolver"
+	"github.com/urfave/cli/v2"
+	"maps"
+	"os"
+	"slices"
+)
+
+// lockChanges describes the difference between the packages of two lockfiles.
+func lockChanges(previous *lockfile.Lockfile, next *lockfile.Lockfile) []string {
+	before := make(map[string]config.Version)
+	if previous != nil {
+		for _, pkg := range previous.Packages {
+			before[pkg.Name] = pkg.Version
+		}
+	}
+	after := make(map[string]config.Version)
+	for _, pkg := range next.Packages {
+		after[pkg.Name] = pkg.Version
+	}
+
+	names := slices.Sorted(maps.Keys(before))
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	changes := make([]string, 0)
+	for _, name := range names {
+		old, hadOld := before[name]
+		updated, hasUpdated := after[name]
+
+		switch {
+		case !hadOld:
+			changes = append(changes, fmt.Sprintf("+ %s %s", name, updated))
+		case !hasUpdated:
+			changes = append(changes, fmt.Sprintf("- %s %s", name, old))
+		case old.Compare(updated) != 0:
+			changes = append(changes, fmt.Sprintf("~ %s %s -> %s", name, old, updated))
+		}
+	}
+
+	return changes
+}
+
+func update(cCtx *cli.Context) error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	lock, err := lockfile.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", lockfile.FileName, err)
+	}
+
+	// with no packages specified, every direct requirement is updated
+	targets := make(map[string]bool)
+	for _, name := range cCtx.Args().Slice() {
+		if !slices.ContainsFunc(cfg.Project.Requires, func(req config.Requirement) bool { return req.Name == name }) {
+			return fmt.Errorf("package %s is not a direct requirement of the project", name)
+		}
+		targets[name] = true
+	}
+
+	repo, err := index.Open(internal.IndexRepositoryUrl)
+	if err != nil {
+		return err
+	}
+
+	// requirements pinned to an exact version are relaxed to allow any newer version, so the resolver can choose the
+	// newest versions that are compatible with each other
+	resolving := slices.Clone(cfg.Project.Requires)
+	for i, req := range resolving {
+		if len(targets) > 0 && !targets[req.Name] {
+			continue
+		}
+
+		if current, ok := req.Constraint.Exact(); ok {
+			if resolving[i].Constraint, err = config.ParseConstraint(">=" + current.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// every other locked package, including direct requirements that are not being updated, is preferred at its
+	// locked version so that updating some packages does not move unrelated ones. They are not pinned, so they may
+	// still move where the updated packages require it
+	preferred := make(map[string]config.Version)
+	if lock != nil && len(targets) > 0 {
+		for _, pkg := range lock.Packages {
+			if !targets[pkg.Name] {
+				preferred[pkg.Name] = pkg.Version
+			}
+		}
+	}
+
+	resolved, err := resolver.ResolvePreferring(repo, resolving, preferred)
+	if err != nil {
+		return err
+	}
+
+	// relaxed requirements are pinned again to the version that was chosen for them
+	requires := slices.Clone(cfg.Project.Requires)
+	for i, req := range requires {
+		if _, ok := req.Constraint.Exact(); ok && (len(targets) == 0 || targets[req.Name]) {
+			requires[i].Constraint = config.ExactConstraint(resolved[req.Name])
+			if requires[i].String() != req.String() {
+				logging.Unquiet("%s: %s -> %s", internal.ConfigFileName, req, requires[i])
+			}
+		}
+	}
+
+	packages, err := lockPackages(repo, resolved)
+	if err != nil {
+		return err
+	}
+	updated := &lockfile.Lockfile{Requires: requires, Packages: packages}
+
+	changes := lockChanges(lock, updated)
+	for _, change := range changes {
+		logging.Unquiet("%s: %s", lockfile.FileName, change)
+	}
+
+	if len(changes) == 0 && slices.EqualFunc(requires, cfg.Project.Requires, func(a, b config.Requirement) bool {
+		return a.String() == b.String()
+	}) {
+		logging.Unquiet("all packages are already up-to-date")
+		return nil
+	}
+
+	if cCtx.Bool("dry-run") {
+		logging.Unquiet("dry run - no files were modified")
+		return nil
+	}
+
+	cfg.Project.Requires = requires
+	if err = config.ToFile(pwd, cfg); err != nil {
+		return fmt.Errorf("failed to update '%s' - %s", internal.ConfigFileName, err)
+	}
+	if err = lockfile.ToFile(pwd, updated); err != nil {
+		return fmt.Errorf("failed to update '%s' - %s", lockfile.FileName, err)
+	}
+
+	logging.Unquiet("updated %d package(s) - run 'proofman install' to apply them", len(changes))
+
+	return nil
+}
+
+var UpdateCommand = &cli.Command{
+	Name:      "update",
+	Usage:     "Updates the project requirements and lockfile to the newest compatible versions in the index repository",
+	ArgsUsage: "[<package>...]",
+	Action:    update,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print the changes that would be made without modifying any files",
+		},
+	},
+}
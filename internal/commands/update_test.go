@@ -0,0 +1,56 @@
+package commands
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/pkg/config"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	assert := asrt.New(t)
+
+	repo := testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {"B"},
+		"B @ 2024-01-01": {},
+		"C @ 2024-01-01": {},
+	})
+	directory := testProject(t, "A @ 2024-01-01", "B @ >=2024-01-01", "C @ >=2024-01-01")
+	assert.NoError(runCommand(LockCommand))
+
+	addIndexVersions(t, repo, map[string][]string{
+		"A @ 2024-06-01": {"B @ >=2024-06-01"},
+		"B @ 2024-06-01": {},
+		"C @ 2024-06-01": {},
+	})
+
+	// dry runs do not modify any files
+	assert.NoError(runCommand(UpdateCommand, "--dry-run", "A"))
+	assert.Equal(map[string]string{"A": "2024-01-01", "B": "2024-01-01", "C": "2024-01-01"}, lockedVersions(t, directory))
+
+	// direct requirements that are not being updated may still move where the updated packages require it, but
+	// unrelated packages keep their locked versions
+	assert.NoError(runCommand(UpdateCommand, "A"))
+	assert.Equal(map[string]string{"A": "2024-06-01", "B": "2024-06-01", "C": "2024-01-01"}, lockedVersions(t, directory))
+
+	cfg, err := config.FromFile(directory)
+	assert.NoError(err)
+	assert.Equal(mustRequirement(t, "A @ 2024-06-01").String(), cfg.Project.Requires[0].String())
+	assert.Equal(mustRequirement(t, "B @ >=2024-01-01").String(), cfg.Project.Requires[1].String())
+
+	// updating every package moves the remaining ones
+	assert.NoError(runCommand(UpdateCommand))
+	assert.Equal(map[string]string{"A": "2024-06-01", "B": "2024-06-01", "C": "2024-06-01"}, lockedVersions(t, directory))
+}
+
+func TestUpdateRejectsIndirectPackages(t *testing.T) {
+	assert := asrt.New(t)
+
+	testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {"B"},
+		"B @ 2024-01-01": {},
+	})
+	testProject(t, "A @ 2024-01-01")
+	assert.NoError(runCommand(LockCommand))
+
+	assert.ErrorContains(runCommand(UpdateCommand, "B"), "package B is not a direct requirement of the project")
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/urfave/cli/v2"
+	"maps"
+	"os"
+	"slices"
+)
+
+// requirementEdges builds the package requirement graph of the project, where each edge is annotated with the
+// reason that the requiring package needs the required package. The project itself is represented by the empty name.
+func requirementEdges(
+	projectDirectory string,
+	cfg *config.ProofmanConfig,
+	manifests map[string]*config.ProofmanConfig,
+) map[string]map[string]string {
+	sessionPackages := make(map[string]string)
+	for name, manifest := range manifests {
+		for _, session := range manifest.Sessions {
+			sessionPackages[session.Name] = name
+		}
+	}
+
+	edges := make(map[string]map[string]string)
+	addEdge := func(from, to, reason string) {
+		if _, ok := manifests[to]; !ok || from == to {
+			return
+		}
+		if _, ok := edges[from]; !ok {
+			edges[from] = make(map[string]string)
+		}
+		if _, ok := edges[from][to]; !ok {
+			edges[from][to] = reason
+		}
+	}
+	addSession := func(from, session, reason string) {
+		if pkg, ok := sessionPackages[session]; ok {
+			addEdge(from, pkg, reason)
+		}
+	}
+
+	used, err := usedSessions(projectDirectory)
+	if err != nil {
+		logging.Verbose("cannot determine sessions used by the project - %s", err)
+	}
+	for _, session := range slices.Sorted(maps.Keys(used)) {
+		addSession("", session, used[session])
+	}
+	for _, req := range cfg.Project.Requires {
+		addEdge("", req.Name, fmt.Sprintf("%s requires %s", internal.ConfigFileName, req))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(manifests)) {
+		manifest := manifests[name]
+
+		for _, session := range manifest.Sessions {
+			if session.Parent != "" {
+				addSession(name, session.Parent, fmt.Sprintf("session %s: ROOT parent session %s", session.Name, session.Parent))
+			}
+			for _, required := range session.Requires {
+				addSession(name, required, fmt.Sprintf("session %s: ROOT sessions entry %s", session.Name, required))
+			}
+			for _, imported := range session.Imports {
+				addSession(name, imported, fmt.Sprintf("session %s: theories import from %s", session.Name, imported))
+			}
+		}
+		for _, req := range manifest.Project.Requires {
+			addEdge(name, req.Name, fmt.Sprintf("%s requires %s", internal.ConfigFileName, req))
+		}
+	}
+
+	return edges
+}
+
+// shortestPaths returns every shortest path from the project to the target package through the given edges.
+func shortestPaths(edges map[string]map[string]string, target string) [][]string {
+	distance := map[string]int{"": 0}
+	predecessors := make(map[string][]string)
+
+	queue := []string{""}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range slices.Sorted(maps.Keys(edges[current])) {
+			d, seen := distance[next]
+			if !seen {
+				distance[next] = distance[current] + 1
+				queue = append(queue, next)
+			}
+			if !seen || d == distance[current]+1 {
+				predecessors[next] = append(predecessors[next], current)
+			}
+		}
+	}
+
+	if _, ok := distance[target]; !ok {
+		return nil
+	}
+
+	var walk func(node string) [][]string
+	walk = func(node string) [][]string {
+		if node == "" {
+			return [][]string{{""}}
+		}
+
+		paths := make([][]string, 0)
+		for _, pred := range predecessors[node] {
+			for _, path := range walk(pred) {
+				paths = append(paths, append(path, node))
+			}
+		}
+		return paths
+	}
+
+	return walk(target)
+}
+
+func why(cCtx *cli.Context) error {
+	if cCtx.NArg() != 1 {
+		return fmt.Errorf("exactly one package is required")
+	}
+	target := cCtx.Args().First()
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromFile(pwd)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' - %s", internal.ConfigFileName, err)
+	}
+
+	_, graph, err := projectGraph(pwd, cfg)
+	if err != nil {
+		return err
+	}
+	if _, ok := graph[target]; !ok {
+		return fmt.Errorf("package %s is not a dependency of the project", target)
+	}
+
+	manifests, err := graphManifests(pwd, graph)
+	if err != nil {
+		return err
+	}
+
+	edges := requirementEdges(pwd, cfg, manifests)
+	paths := shortestPaths(edges, target)
+	if len(paths) == 0 {
+		return fmt.Errorf("no requirement path from the project to %s could be found", target)
+	}
+
+	logging.Quiet("%s %s is required through %d shortest path(s):", target, graph[target].Version, len(paths))
+	for _, path := range paths {
+		logging.Quiet("")
+		logging.Quiet("%s %s", cfg.Project.Name, cfg.Project.Version)
+
+		for i := 1; i < len(path); i++ {
+			from, to := path[i-1], path[i]
+			logging.QuietIndented(1, "-> %s %s (%s)", to, graph[to].Version, edges[from][to])
+		}
+	}
+
+	return nil
+}
+
+var WhyCommand = &cli.Command{
+	Name:      "why",
+	Usage:     "Explains why a package is a dependency of the current project",
+	ArgsUsage: "<package>",
+	Action:    why,
+}
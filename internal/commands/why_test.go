@@ -0,0 +1,58 @@
+package commands
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestShortestPaths(t *testing.T) {
+	assert := asrt.New(t)
+
+	edges := map[string]map[string]string{
+		"":  {"A": "", "B": "", "E": ""},
+		"A": {"C": ""},
+		"B": {"C": ""},
+		"C": {"D": ""},
+		"E": {"F": ""},
+		"F": {"G": ""},
+		"G": {"D": ""},
+	}
+
+	// the longer path through E is not returned
+	assert.Equal([][]string{{"", "A", "C", "D"}, {"", "B", "C", "D"}}, shortestPaths(edges, "D"))
+	assert.Equal([][]string{{"", "E", "F"}}, shortestPaths(edges, "F"))
+	assert.Nil(shortestPaths(edges, "Missing"))
+}
+
+func TestWhy(t *testing.T) {
+	assert := asrt.New(t)
+
+	testIndex(t, map[string][]string{
+		"A @ 2024-01-01": {"C"},
+		"B @ 2024-01-01": {"C"},
+		"C @ 2024-01-01": {"D"},
+		"D @ 2024-01-01": {},
+	})
+	testProject(t, "A @ 2024-01-01", "B @ 2024-01-01")
+	assert.NoError(runCommand(LockCommand))
+
+	output, err := captureStdout(t, func() error { return runCommand(WhyCommand, "D") })
+	assert.NoError(err)
+	assert.Equal(strings.Join([]string{
+		"D 2024-01-01 is required through 2 shortest path(s):",
+		"",
+		"Project 2024-01-01",
+		"  -> A 2024-01-01 (proofman.toml requires A @ 2024-01-01)",
+		"  -> C 2024-01-01 (proofman.toml requires C)",
+		"  -> D 2024-01-01 (proofman.toml requires D)",
+		"",
+		"Project 2024-01-01",
+		"  -> B 2024-01-01 (proofman.toml requires B @ 2024-01-01)",
+		"  -> C 2024-01-01 (proofman.toml requires C)",
+		"  -> D 2024-01-01 (proofman.toml requires D)",
+	}, "\n")+"\n", output)
+
+	assert.EqualError(runCommand(WhyCommand, "Missing"), "package Missing is not a dependency of the project")
+	assert.EqualError(runCommand(WhyCommand), "exactly one package is required")
+}
@@ -2,4 +2,6 @@ package internal
 
 const (
 	ConfigFileName = "proofman.toml"
+	VenvDirName    = ".venv"
+	DepsDirName    = "deps"
 )
@@ -10,3 +10,4 @@ var LogLevel = LogLvlUnquiet
 
 var ProofbankBaseUrl = "" // TODO - change this to a sane default
 var ProofbankApiToken = ""
+var IndexRepositoryUrl = ""
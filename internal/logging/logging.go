@@ -6,24 +6,42 @@ import (
 	"strings"
 )
 
-func log(message string, args ...any) {
-	fmt.Printf(strings.TrimSpace(message)+"\n", args...)
+// indentWidth is the number of spaces that each level of indentation is made up of.
+const indentWidth = 2
+
+func log(indent int, message string, args ...any) {
+	fmt.Printf(strings.Repeat(" ", indent*indentWidth)+strings.TrimSpace(message)+"\n", args...)
 }
 
 func Verbose(message string, args ...any) {
+	VerboseIndented(0, message, args...)
+}
+
+// VerboseIndented is Verbose, but the message is indented by the given number of levels.
+func VerboseIndented(indent int, message string, args ...any) {
 	if internal.LogLevel >= internal.LogLvlVerbose {
-		log(message, args...)
+		log(indent, message, args...)
 	}
 }
 
 func Unquiet(message string, args ...any) {
+	UnquietIndented(0, message, args...)
+}
+
+// UnquietIndented is Unquiet, but the message is indented by the given number of levels.
+func UnquietIndented(indent int, message string, args ...any) {
 	if internal.LogLevel >= internal.LogLvlUnquiet {
-		log(message, args...)
+		log(indent, message, args...)
 	}
 }
 
 func Quiet(message string, args ...any) {
+	QuietIndented(0, message, args...)
+}
+
+// QuietIndented is Quiet, but the message is indented by the given number of levels.
+func QuietIndented(indent int, message string, args ...any) {
 	if internal.LogLevel >= internal.LogLvlQuiet {
-		log(message, args...)
+		log(indent, message, args...)
 	}
 }
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/internal"
+	"io"
+	"os"
+	"testing"
+)
+
+// captureStdout returns everything written to stdout by the given function.
+func captureStdout(t *testing.T, f func()) string {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	f()
+	_ = writer.Close()
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(output)
+}
+
+func TestIndented(t *testing.T) {
+	assert := asrt.New(t)
+
+	level := internal.LogLevel
+	defer func() { internal.LogLevel = level }()
+	internal.LogLevel = internal.LogLvlUnquiet
+
+	output := captureStdout(t, func() {
+		Unquiet("  header: %s  ", "value")
+		UnquietIndented(1, "- %s", "first")
+		QuietIndented(2, "  %s", "second")
+		VerboseIndented(1, "hidden")
+	})
+	assert.Equal("header: value\n  - first\n    second\n", output)
+}
@@ -0,0 +1,61 @@
+package checksum
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const prefix = "sha256:"
+
+// Directory computes a hash of the contents of a directory tree. The hash covers the relative path and the
+// content of every regular file within the tree, so any added, removed, renamed or modified file will change
+// the result. Files whose path relative to root is within ignore are excluded from the hash.
+func Directory(root string, ignore ...string) (string, error) {
+	hasher := sha256.New()
+
+	// WalkDir visits entries in lexical order, so the resulting hash is deterministic
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if slices.Contains(ignore, rel) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(hasher, "%s\x00%d\x00", rel, info.Size())
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(hasher, file)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + hex.EncodeToString(hasher.Sum(nil)), nil
+}
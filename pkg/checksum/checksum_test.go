@@ -0,0 +1,87 @@
+package checksum
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type file struct {
+	path    string
+	content string
+}
+
+func writeTree(t *testing.T, files ...file) string {
+	root := t.TempDir()
+	for _, f := range files {
+		path := filepath.Join(root, f.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestDirectory(t *testing.T) {
+	original := []file{{"ROOT", "session A"}, {"A.thy", "theory A"}, {"doc/root.tex", "tex"}}
+	expected, err := Directory(writeTree(t, original...))
+	asrt.New(t).NoError(err)
+
+	tests := []struct {
+		name   string
+		files  []file
+		ignore []string
+		equal  bool
+	}{
+		{
+			name:  "files created in a different order",
+			files: []file{{"doc/root.tex", "tex"}, {"A.thy", "theory A"}, {"ROOT", "session A"}},
+			equal: true,
+		},
+		{
+			name:   "ignored file added",
+			files:  append([]file{{"proofman.toml", "[project]"}}, original...),
+			ignore: []string{"proofman.toml"},
+			equal:  true,
+		},
+		{
+			name:  "file modified",
+			files: []file{{"ROOT", "session A"}, {"A.thy", "theory B"}, {"doc/root.tex", "tex"}},
+		},
+		{
+			name:  "file added",
+			files: append([]file{{"B.thy", "theory B"}}, original...),
+		},
+		{
+			name:  "file removed",
+			files: original[:2],
+		},
+		{
+			name:  "file renamed",
+			files: []file{{"ROOT", "session A"}, {"B.thy", "theory A"}, {"doc/root.tex", "tex"}},
+		},
+		{
+			name:  "content moved between files",
+			files: []file{{"ROOT", "session A"}, {"A.thy", "theory "}, {"doc/root.tex", "Atex"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := asrt.New(t)
+
+			hash, err := Directory(writeTree(t, test.files...), test.ignore...)
+			assert.NoError(err)
+			if test.equal {
+				assert.Equal(expected, hash)
+			} else {
+				assert.NotEqual(expected, hash)
+			}
+		})
+	}
+}
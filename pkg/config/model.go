@@ -1,12 +1,90 @@
 package config
 
+import (
+	"maps"
+	"slices"
+)
+
 type Project struct {
-	Name        string   `toml:"name"`
-	Description string   `toml:"description"`
-	Version     string   `toml:"version"`
-	Requires    []string `toml:"requires"`
+	Name        string        `toml:"name"`
+	Description string        `toml:"description"`
+	Version     string        `toml:"version"`
+	Requires    []Requirement `toml:"requires"`
+
+	// the remaining fields are optional metadata, filled from the AFP entry metadata for indexed packages
+	Title    string   `toml:"title,omitempty"`
+	Authors  []string `toml:"authors,omitempty"`
+	License  string   `toml:"license,omitempty"`
+	Topics   []string `toml:"topics,omitempty"`
+	Homepage string   `toml:"homepage,omitempty"`
+	Abstract string   `toml:"abstract,omitempty"`
+}
+
+// Session describes a session provided by an indexed package. Sessions are only present in the manifests
+// written by the indexer.
+type Session struct {
+	Name     string   `toml:"name"`
+	Parent   string   `toml:"parent,omitempty"`
+	Requires []string `toml:"requires,omitempty"`
+	// Imports are the sessions referenced by qualified imports within the theories of the session
+	Imports []string `toml:"imports,omitempty"`
 }
 
 type ProofmanConfig struct {
-	Project Project `toml:"project"`
+	// Checksum is a hash of the package contents, excluding the manifest itself. It is only present in the
+	// manifests written by the indexer, which uses it to detect packages that have not changed between versions.
+	Checksum string    `toml:"checksum,omitempty"`
+	Project  Project   `toml:"project"`
+	Sessions []Session `toml:"sessions,omitempty"`
+}
+
+// ProvidedSessions returns the names of the sessions provided by an indexed package.
+func (c *ProofmanConfig) ProvidedSessions() []string {
+	provided := make([]string, 0, len(c.Sessions))
+	for _, session := range c.Sessions {
+		provided = append(provided, session.Name)
+	}
+
+	return provided
+}
+
+// ImportedSessions returns the sessions required by an indexed package only through the theory imports of its
+// sessions, that are not listed within its ROOT file. Sessions provided by the package itself are excluded.
+func (c *ProofmanConfig) ImportedSessions() []string {
+	provided := c.ProvidedSessions()
+
+	imported := make(map[string]bool)
+	for _, session := range c.Sessions {
+		for _, s := range session.Imports {
+			if s != session.Parent && !slices.Contains(session.Requires, s) && !slices.Contains(provided, s) {
+				imported[s] = true
+			}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(imported))
+}
+
+// RequiredSessions returns the sessions required by an indexed package, either through its ROOT file - as a parent
+// session or within a 'sessions' block - or through the theory imports of its sessions. Sessions provided by the
+// package itself are excluded.
+func (c *ProofmanConfig) RequiredSessions() []string {
+	provided := c.ProvidedSessions()
+
+	required := make(map[string]bool)
+	for _, session := range c.Sessions {
+		if session.Parent != "" && !slices.Contains(provided, session.Parent) {
+			required[session.Parent] = true
+		}
+		for _, s := range session.Requires {
+			if !slices.Contains(provided, s) {
+				required[s] = true
+			}
+		}
+	}
+	for _, s := range c.ImportedSessions() {
+		required[s] = true
+	}
+
+	return slices.Sorted(maps.Keys(required))
 }
@@ -0,0 +1,19 @@
+package config
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRequiredSessions(t *testing.T) {
+	assert := asrt.New(t)
+
+	cfg := &ProofmanConfig{Sessions: []Session{
+		{Name: "A", Parent: "B", Requires: []string{"C"}, Imports: []string{"B", "C", "D"}},
+		{Name: "A_Examples", Parent: "A", Imports: []string{"A", "E"}},
+	}}
+
+	assert.Equal([]string{"A", "A_Examples"}, cfg.ProvidedSessions())
+	assert.Equal([]string{"D", "E"}, cfg.ImportedSessions())
+	assert.Equal([]string{"B", "C", "D", "E"}, cfg.RequiredSessions())
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const sourceKeyword = "from"
+
+// ErrUnsupportedSource is returned for requirements that name a source. Sources are parsed so that manifests using
+// them remain readable, but packages can currently only be fetched from the index repository.
+var ErrUnsupportedSource = errors.New("requirement sources are not supported yet - packages are always fetched from the index repository")
+
+// Requirement is a single entry in a project's requires list. Requirements are written using the syntax
+//
+//	name [@ constraint] [from source]
+//
+// for example 'Collections @ 2024-05-12' or 'Collections from https://example.com/index.git'.
+type Requirement struct {
+	Name       string
+	Constraint Constraint
+	Source     string
+}
+
+// RequirementError describes a malformed requirement, version or version constraint. Offset is the 0-based byte offset within Input at
+// which the problem was detected.
+type RequirementError struct {
+	// Kind is what was being parsed, such as 'version' - an empty kind is a requirement
+	Kind    string
+	Input   string
+	Offset  int
+	Message string
+	// Err is the underlying cause of the error, if any. It is only available through errors.Unwrap, as Message
+	// already describes the problem
+	Err error
+}
+
+func (e *RequirementError) Error() string {
+	kind := e.Kind
+	if kind == "" {
+		kind = "requirement"
+	}
+
+	return fmt.Sprintf("invalid %s %q - column %d: %s", kind, e.Input, e.Offset+1, e.Message)
+}
+
+func (e *RequirementError) Unwrap() error {
+	return e.Err
+}
+
+type requirementScanner struct {
+	input string
+	pos   int
+}
+
+func (s *requirementScanner) skipSpace() {
+	for s.pos < len(s.input) && unicode.IsSpace(rune(s.input[s.pos])) {
+		s.pos++
+	}
+}
+
+func (s *requirementScanner) errorf(offset int, format string, args ...any) *RequirementError {
+	return &RequirementError{Input: s.input, Offset: offset, Message: fmt.Sprintf(format, args...)}
+}
+
+// word consumes the next run of non-whitespace characters.
+func (s *requirementScanner) word() (string, int) {
+	start := s.pos
+	for s.pos < len(s.input) && !unicode.IsSpace(rune(s.input[s.pos])) {
+		s.pos++
+	}
+
+	return s.input[start:s.pos], start
+}
+
+// isNameChar reports whether c may appear within a package name, matching NamePattern.
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// ParseRequirement parses a requirement string. Any error returned will be a *RequirementError.
+func ParseRequirement(input string) (Requirement, error) {
+	s := &requirementScanner{input: input}
+	req := Requirement{}
+
+	s.skipSpace()
+	start := s.pos
+	for s.pos < len(input) && isNameChar(input[s.pos]) {
+		s.pos++
+	}
+	if s.pos == start {
+		return req, s.errorf(start, "expected package name")
+	}
+	req.Name = input[start:s.pos]
+
+	s.skipSpace()
+	if s.pos < len(input) && input[s.pos] == '@' {
+		s.pos++
+		s.skipSpace()
+
+		// the constraint runs until the source keyword or the end of the input
+		constraintStart := s.pos
+		constraintEnd := s.pos
+		for s.pos < len(input) {
+			word, _ := s.word()
+			if word == sourceKeyword {
+				s.pos -= len(word)
+				break
+			}
+
+			constraintEnd = s.pos
+			s.skipSpace()
+		}
+
+		if constraintEnd == constraintStart {
+			return req, s.errorf(constraintStart, "expected version constraint after '@'")
+		}
+		constraint, err := ParseConstraint(input[constraintStart:constraintEnd])
+		if err != nil {
+			reqErr := s.errorf(constraintStart+err.(*RequirementError).Offset, "%s", err.(*RequirementError).Message)
+			reqErr.Err = err.(*RequirementError).Err
+			return req, reqErr
+		}
+		req.Constraint = constraint
+	}
+
+	s.skipSpace()
+	if s.pos < len(input) {
+		word, offset := s.word()
+		if word != sourceKeyword {
+			return req, s.errorf(offset, "unexpected %q, expected '@' or '%s'", word, sourceKeyword)
+		}
+
+		s.skipSpace()
+		source, offset := s.word()
+		if source == "" {
+			return req, s.errorf(offset, "expected source after '%s'", sourceKeyword)
+		}
+		req.Source = source
+
+		s.skipSpace()
+		if s.pos < len(input) {
+			return req, s.errorf(s.pos, "unexpected trailing input %q", input[s.pos:])
+		}
+	}
+
+	return req, nil
+}
+
+// Validate checks that the requirement could have been produced by ParseRequirement, which may not be the case
+// for requirements that were constructed directly.
+func (r Requirement) Validate() error {
+	parsed, err := ParseRequirement(r.String())
+	if err != nil {
+		return err
+	}
+
+	if parsed.String() != r.String() || parsed.Name != r.Name || parsed.Source != r.Source {
+		return &RequirementError{Input: r.String(), Offset: 0, Message: "requirement does not round-trip through its string form"}
+	}
+
+	return nil
+}
+
+func (r Requirement) String() string {
+	var b strings.Builder
+	b.WriteString(r.Name)
+
+	if constraint := r.Constraint.String(); constraint != "" {
+		b.WriteString(" @ ")
+		b.WriteString(constraint)
+	}
+	if r.Source != "" {
+		b.WriteString(" " + sourceKeyword + " ")
+		b.WriteString(r.Source)
+	}
+
+	return b.String()
+}
+
+func (r Requirement) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Requirement) UnmarshalText(text []byte) error {
+	parsed, err := ParseRequirement(string(text))
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+	return nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"github.com/pelletier/go-toml/v2"
+	asrt "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func mustConstraint(input string) Constraint {
+	constraint, err := ParseConstraint(input)
+	if err != nil {
+		panic(err)
+	}
+
+	return constraint
+}
+
+func TestParseRequirement(t *testing.T) {
+	assert := asrt.New(t)
+
+	cases := []struct {
+		input string
+		want  Requirement
+	}{
+		{"Collections", Requirement{Name: "Collections"}},
+		{"Collections@2024-05-12", Requirement{Name: "Collections", Constraint: mustConstraint("2024-05-12")}},
+		{"Collections @ 2024-05-12", Requirement{Name: "Collections", Constraint: mustConstraint("2024-05-12")}},
+		{"  HOL-Library @ >=2023-01-01, <2025  ", Requirement{Name: "HOL-Library", Constraint: mustConstraint(">=2023-01-01, <2025")}},
+		{"Foo from https://example.com/index.git", Requirement{Name: "Foo", Source: "https://example.com/index.git"}},
+		{"Foo @ 2024 from local", Requirement{Name: "Foo", Constraint: mustConstraint("2024"), Source: "local"}},
+	}
+
+	for _, tt := range cases {
+		req, err := ParseRequirement(tt.input)
+		assert.NoError(err, "Unexpected error for input: %s", tt.input)
+		assert.Equal(tt.want, req, "Unexpected requirement for input: %s", tt.input)
+	}
+}
+
+func TestParseRequirementErrors(t *testing.T) {
+	assert := asrt.New(t)
+
+	cases := []struct {
+		input  string
+		offset int
+	}{
+		{"", 0},
+		{"@ 2024", 0},
+		{"Foo @", 5},
+		{"Foo @ from x", 6},
+		{"Foo @ >=2024-13-01", 13},
+		{"Foo @ >=2023-01-01, <20x", 21},
+		{"Foo bar", 4},
+		{"Foo from", 8},
+		{"Foo from a b", 11},
+	}
+
+	for _, tt := range cases {
+		_, err := ParseRequirement(tt.input)
+
+		var reqErr *RequirementError
+		if assert.True(errors.As(err, &reqErr), "Expected a RequirementError for input: %s", tt.input) {
+			assert.Equal(tt.offset, reqErr.Offset, "Unexpected error offset for input: %s", tt.input)
+		}
+	}
+}
+
+func TestRequirementRoundTrip(t *testing.T) {
+	assert := asrt.New(t)
+
+	cfg := Default()
+	cfg.Project.Requires = []Requirement{
+		{Name: "Collections", Constraint: mustConstraint("2024-05-12")},
+		{Name: "Foo", Constraint: mustConstraint(">=2023-01-01"), Source: "local"},
+		{Name: "Bar"},
+	}
+
+	marshalled, err := toml.Marshal(cfg)
+	assert.NoError(err)
+
+	parsed := &ProofmanConfig{}
+	assert.NoError(toml.Unmarshal(marshalled, parsed))
+	assert.Equal(cfg.Project.Requires, parsed.Project.Requires)
+}
+
+func TestValidateRejectsMalformedRequirements(t *testing.T) {
+	assert := asrt.New(t)
+
+	cfg := Default()
+	cfg.Project.Requires = []Requirement{{Name: "Foo Bar"}}
+	assert.Error(Validate(cfg))
+
+	cfg.Project.Requires = []Requirement{{Name: "Foo"}, {Name: "Foo", Constraint: mustConstraint("2024")}}
+	assert.Error(Validate(cfg))
+
+	cfg.Project.Requires = []Requirement{{Name: "Foo", Constraint: mustConstraint("2024")}}
+	assert.NoError(Validate(cfg))
+
+	cfg.Project.Requires = []Requirement{{Name: "Foo", Constraint: mustConstraint("2024"), Source: "https://example.com/index.git"}}
+	assert.ErrorIs(Validate(cfg), ErrUnsupportedSource)
+}
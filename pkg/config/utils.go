@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/tandemdude/proofman/internal"
 	"os"
@@ -20,6 +22,12 @@ func FromFile(directory string) (*ProofmanConfig, error) {
 	err = toml.Unmarshal(contents, cfg)
 	if err != nil {
 		// file exists but is invalid
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			row, column := decodeErr.Position()
+			return nil, fmt.Errorf("%s:%d:%d: %w", cfgFilePath, row, column, err)
+		}
+
 		return nil, err
 	}
 
@@ -30,13 +38,22 @@ func FromFile(directory string) (*ProofmanConfig, error) {
 	return cfg, nil
 }
 
+func ToFile(directory string, cfg *ProofmanConfig) error {
+	marshalled, err := toml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	return internal.WriteFile(path.Join(directory, internal.ConfigFileName), string(marshalled), false)
+}
+
 func Default() *ProofmanConfig {
 	return &ProofmanConfig{
 		Project: Project{
 			Name:        "NewProject",
 			Description: "New Isabelle project using Proofman",
 			Version:     time.Now().Format(time.DateOnly),
-			Requires:    []string{},
+			Requires:    []Requirement{},
 		},
 	}
 }
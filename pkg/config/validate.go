@@ -5,9 +5,11 @@ import (
 	"regexp"
 )
 
+// MaxDescriptionLength is the maximum length of a project description, in bytes.
+const MaxDescriptionLength = 100
+
 var (
-	NamePattern    = regexp.MustCompile(`^[\w-]+$`)
-	VersionPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	NamePattern = regexp.MustCompile(`^[\w-]+$`)
 )
 
 func Validate(cfg *ProofmanConfig) error {
@@ -17,13 +19,29 @@ func Validate(cfg *ProofmanConfig) error {
 	}
 
 	// Description can be from 0-100 chars
-	if len(cfg.Project.Description) > 100 {
-		return fmt.Errorf("project description is too long - should be 0-100 chars")
+	if len(cfg.Project.Description) > MaxDescriptionLength {
+		return fmt.Errorf("project description is too long - should be 0-%d chars", MaxDescriptionLength)
+	}
+
+	// Version MUST be a date (YYYY-MM-DD) or Isabelle release (YYYY or YYYY-N) version
+	if _, err := ParseVersion(cfg.Project.Version); err != nil {
+		return fmt.Errorf("project version is invalid - %w", err)
 	}
 
-	// Version MUST be in format YYYY-MM-DD
-	if !VersionPattern.MatchString(cfg.Project.Version) {
-		return fmt.Errorf("project version is invalid - must match %s", VersionPattern.String())
+	// Requirements must be well-formed, and each package may only be required once
+	seen := make(map[string]int)
+	for i, req := range cfg.Project.Requires {
+		if err := req.Validate(); err != nil {
+			return fmt.Errorf("project requires[%d] is invalid - %w", i, err)
+		}
+		if req.Source != "" {
+			return fmt.Errorf("project requires[%d] is invalid - %w", i, ErrUnsupportedSource)
+		}
+
+		if first, ok := seen[req.Name]; ok {
+			return fmt.Errorf("project requires[%d] is invalid - package %s is already required by requires[%d]", i, req.Name, first)
+		}
+		seen[req.Name] = i
 	}
 
 	return nil
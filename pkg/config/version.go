@@ -0,0 +1,294 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// VersionPattern matches the syntax of a date or Isabelle release version. Date versions matching it may still
+	// be invalid, such as '2024-02-31' - use ParseVersion to check that a version is valid.
+	VersionPattern = regexp.MustCompile(`^(?:\d{4}-\d{2}-\d{2}|(?:Isabelle)?\d{4}(?:-[1-9]\d*)?)$`)
+
+	dateVersionPattern    = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	releaseVersionPattern = regexp.MustCompile(`^(?:Isabelle)?(\d{4})(?:-([1-9]\d*))?$`)
+)
+
+const dateVersionLayout = "2006-01-02"
+
+// Version is either a date version ('2024-05-12'), as used by AFP snapshots and projects, or an Isabelle
+// release version ('2024', '2024-1', optionally prefixed with 'Isabelle'), as used by AFP releases.
+//
+// Versions are ordered by year first. Within the same year release versions are ordered by their release
+// number, date versions are ordered by date, and release versions are ordered before all date versions.
+type Version struct {
+	Year    int
+	Month   int
+	Day     int
+	Release int
+
+	isRelease bool
+}
+
+// ParseVersion parses a date or Isabelle release version. Any error returned will be a *RequirementError.
+func ParseVersion(input string) (Version, error) {
+	if match := dateVersionPattern.FindStringSubmatch(input); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+
+		if month < 1 || month > 12 {
+			return Version{}, &RequirementError{
+				Kind:    "version",
+				Input:   input,
+				Offset:  5,
+				Message: "month must be between 01 and 12",
+			}
+		}
+
+		date, err := time.Parse(dateVersionLayout, input)
+		if err != nil {
+			return Version{}, &RequirementError{
+				Kind:    "version",
+				Input:   input,
+				Offset:  8,
+				Message: fmt.Sprintf("day %s does not exist in %s %d", match[3], time.Month(month), year),
+				Err:     err,
+			}
+		}
+
+		return Version{Year: date.Year(), Month: int(date.Month()), Day: date.Day()}, nil
+	}
+
+	if match := releaseVersionPattern.FindStringSubmatch(input); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		release := 0
+		if match[2] != "" {
+			release, _ = strconv.Atoi(match[2])
+		}
+
+		return Version{Year: year, Release: release, isRelease: true}, nil
+	}
+
+	return Version{}, &RequirementError{
+		Kind:    "version",
+		Input:   input,
+		Offset:  0,
+		Message: "expected a date version (YYYY-MM-DD) or an Isabelle release version (YYYY or YYYY-N)",
+	}
+}
+
+// IsRelease reports whether the version is an Isabelle release version rather than a date version.
+func (v Version) IsRelease() bool {
+	return v.isRelease
+}
+
+// Compare returns -1, 0 or +1 depending on whether v orders before, equal to or after other.
+func (v Version) Compare(other Version) int {
+	if c := cmp.Compare(v.Year, other.Year); c != 0 {
+		return c
+	}
+
+	if v.isRelease != other.isRelease {
+		if v.isRelease {
+			return -1
+		}
+		return 1
+	}
+
+	if v.isRelease {
+		return cmp.Compare(v.Release, other.Release)
+	}
+
+	if c := cmp.Compare(v.Month, other.Month); c != 0 {
+		return c
+	}
+	return cmp.Compare(v.Day, other.Day)
+}
+
+func (v Version) String() string {
+	if !v.isRelease {
+		return fmt.Sprintf("%04d-%02d-%02d", v.Year, v.Month, v.Day)
+	}
+
+	if v.Release == 0 {
+		return fmt.Sprintf("%04d", v.Year)
+	}
+	return fmt.Sprintf("%04d-%d", v.Year, v.Release)
+}
+
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+func (v *Version) UnmarshalText(text []byte) error {
+	parsed, err := ParseVersion(string(text))
+	if err != nil {
+		return err
+	}
+
+	*v = parsed
+	return nil
+}
+
+// constraintOperators lists the supported operators. Longer operators must come before any operators
+// they are prefixed by so that they are matched first.
+var constraintOperators = []string{"==", "!=", ">=", "<=", ">", "<", "~"}
+
+type constraintClause struct {
+	operator string
+	version  Version
+}
+
+func (c constraintClause) matches(v Version) bool {
+	order := v.Compare(c.version)
+
+	switch c.operator {
+	case "==":
+		return order == 0
+	case "!=":
+		return order != 0
+	case ">=":
+		return order >= 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case "<":
+		return order < 0
+	case "~":
+		// compatible versions are those at or after the given version within the same year
+		return order >= 0 && v.Year == c.version.Year
+	default:
+		panic("unexpected constraint operator " + c.operator)
+	}
+}
+
+// Constraint is a comma separated list of version clauses which must all be satisfied by a version for it to
+// match the constraint. Each clause is a version optionally prefixed with one of the operators '==', '!=', '>=',
+// '<=', '>', '<' or '~', for example '>=2023-01-01, <2025' or '~Isabelle2024'. A clause without an operator is
+// equivalent to '=='. The zero value, and the constraint '*', match any version.
+type Constraint struct {
+	raw     string
+	clauses []constraintClause
+}
+
+// ParseConstraint parses a version constraint. Any error returned will be a *RequirementError.
+func ParseConstraint(input string) (Constraint, error) {
+	constraint := Constraint{raw: strings.TrimSpace(input)}
+	if constraint.raw == "" || constraint.raw == "*" {
+		return constraint, nil
+	}
+
+	offset := 0
+	for _, part := range strings.Split(input, ",") {
+		partOffset := offset + len(part) - len(strings.TrimLeft(part, " \t"))
+		offset += len(part) + 1
+
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return Constraint{}, &RequirementError{
+				Kind:    "version constraint",
+				Input:   input,
+				Offset:  partOffset,
+				Message: "expected version clause",
+			}
+		}
+
+		clause := constraintClause{operator: "=="}
+		for _, operator := range constraintOperators {
+			if strings.HasPrefix(part, operator) {
+				clause.operator = operator
+				part = part[len(operator):]
+				partOffset += len(operator)
+				break
+			}
+		}
+
+		trimmed := strings.TrimLeft(part, " \t")
+		partOffset += len(part) - len(trimmed)
+
+		version, err := ParseVersion(trimmed)
+		if err != nil {
+			reqErr := err.(*RequirementError)
+			return Constraint{}, &RequirementError{
+				Kind:    "version constraint",
+				Input:   input,
+				Offset:  partOffset + reqErr.Offset,
+				Message: reqErr.Message,
+				Err:     reqErr.Err,
+			}
+		}
+		clause.version = version
+
+		constraint.clauses = append(constraint.clauses, clause)
+	}
+
+	return constraint, nil
+}
+
+// ExactConstraint returns a constraint that only matches the given version.
+func ExactConstraint(v Version) Constraint {
+	return Constraint{raw: v.String(), clauses: []constraintClause{{operator: "==", version: v}}}
+}
+
+// IsAny reports whether the constraint matches every version.
+func (c Constraint) IsAny() bool {
+	return len(c.clauses) == 0
+}
+
+// Exact returns the single version matched by the constraint, and whether the constraint matches exactly
+// one version.
+func (c Constraint) Exact() (Version, bool) {
+	if len(c.clauses) != 1 || c.clauses[0].operator != "==" {
+		return Version{}, false
+	}
+
+	return c.clauses[0].version, true
+}
+
+// Matches reports whether the given version satisfies every clause of the constraint.
+func (c Constraint) Matches(v Version) bool {
+	for _, clause := range c.clauses {
+		if !clause.matches(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Newest returns the newest of the given versions that satisfies the constraint.
+func (c Constraint) Newest(versions []Version) (Version, bool) {
+	var newest Version
+	found := false
+
+	for _, v := range versions {
+		if c.Matches(v) && (!found || v.Compare(newest) > 0) {
+			newest, found = v, true
+		}
+	}
+
+	return newest, found
+}
+
+func (c Constraint) String() string {
+	return c.raw
+}
+
+func (c Constraint) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Constraint) UnmarshalText(text []byte) error {
+	parsed, err := ParseConstraint(string(text))
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+	return nil
+}
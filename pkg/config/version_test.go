@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	asrt "github.com/stretchr/testify/assert"
+	"slices"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	assert := asrt.New(t)
+
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"2024-05-12", "2024-05-12"},
+		{"2024", "2024"},
+		{"2021-1", "2021-1"},
+		{"Isabelle2024", "2024"},
+		{"Isabelle2021-1", "2021-1"},
+	}
+
+	for _, tt := range cases {
+		v, err := ParseVersion(tt.input)
+		assert.NoError(err, "Unexpected error for input: %s", tt.input)
+		assert.Equal(tt.want, v.String(), "Expected: %s, got: %s", tt.want, v.String())
+	}
+
+	for _, input := range []string{"", "24", "2024-13-01", "2024-05-32", "2024-05", "Isabelle", "2024-1-1", "2023-02-29", "2024-04-31"} {
+		_, err := ParseVersion(input)
+		assert.Error(err, "Expected an error for input: %s", input)
+	}
+
+	// the pattern only checks the syntax of a version
+	assert.True(VersionPattern.MatchString("2023-02-29"))
+	assert.True(VersionPattern.MatchString("Isabelle2021-1"))
+	assert.False(VersionPattern.MatchString("2024-1-1"))
+
+	v, err := ParseVersion("2024-02-29")
+	assert.NoError(err)
+	assert.Equal("2024-02-29", v.String())
+
+	_, err = ParseVersion("2023-02-29")
+	var reqErr *RequirementError
+	if assert.True(errors.As(err, &reqErr)) {
+		assert.Equal(8, reqErr.Offset)
+		assert.Error(errors.Unwrap(err))
+		assert.Equal(`invalid version "2023-02-29" - column 9: day 29 does not exist in February 2023`, err.Error())
+	}
+
+	_, err = ParseConstraint(">=2024-04-31")
+	assert.Equal(`invalid version constraint ">=2024-04-31" - column 11: day 31 does not exist in April 2024`, err.Error())
+}
+
+func TestVersionOrdering(t *testing.T) {
+	assert := asrt.New(t)
+
+	ordered := []string{"2020", "2021", "2021-1", "2021-03-01", "2021-12-24", "2024", "2024-01-01", "2024-05-12", "2025"}
+
+	versions := make([]Version, 0)
+	for i := len(ordered) - 1; i >= 0; i-- {
+		v, err := ParseVersion(ordered[i])
+		assert.NoError(err)
+		versions = append(versions, v)
+	}
+	slices.SortFunc(versions, Version.Compare)
+
+	sorted := make([]string, 0)
+	for _, v := range versions {
+		sorted = append(sorted, v.String())
+	}
+	assert.Equal(ordered, sorted)
+}
+
+func TestConstraintMatches(t *testing.T) {
+	assert := asrt.New(t)
+
+	cases := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{"", []string{"2020", "2024-05-12"}, nil},
+		{"*", []string{"2020", "2024-05-12"}, nil},
+		{"2024-05-12", []string{"2024-05-12"}, []string{"2024-05-13", "2024"}},
+		{"==2024-05-12", []string{"2024-05-12"}, []string{"2024-05-11"}},
+		{"!=2024", []string{"2024-1", "2023"}, []string{"Isabelle2024"}},
+		{">=2023-01-01", []string{"2023-01-01", "2024", "2025-03-01"}, []string{"2022-12-31", "2023"}},
+		{">=2023-01-01, <2025", []string{"2023-06-01", "2024-12-31"}, []string{"2025", "2025-01-02"}},
+		{"~Isabelle2024", []string{"2024", "2024-1", "2024-05-12"}, []string{"2023", "2025"}},
+		{"~2021-1", []string{"2021-1", "2021-2"}, []string{"2021", "2022"}},
+	}
+
+	for _, tt := range cases {
+		constraint, err := ParseConstraint(tt.constraint)
+		if !assert.NoError(err, "Unexpected error for constraint: %s", tt.constraint) {
+			continue
+		}
+
+		for _, raw := range tt.matches {
+			v, _ := ParseVersion(raw)
+			assert.True(constraint.Matches(v), "Expected %s to match %s", tt.constraint, raw)
+		}
+		for _, raw := range tt.rejects {
+			v, _ := ParseVersion(raw)
+			assert.False(constraint.Matches(v), "Expected %s not to match %s", tt.constraint, raw)
+		}
+	}
+}
+
+func TestConstraintExact(t *testing.T) {
+	assert := asrt.New(t)
+
+	for _, raw := range []string{"2024-05-12", "==2024-05-12"} {
+		constraint, _ := ParseConstraint(raw)
+		v, ok := constraint.Exact()
+		assert.True(ok, "Expected %s to be exact", raw)
+		assert.Equal("2024-05-12", v.String())
+	}
+
+	for _, raw := range []string{"", "*", ">=2024-05-12", "~2024", "2024-05-12, 2024-05-12"} {
+		constraint, _ := ParseConstraint(raw)
+		_, ok := constraint.Exact()
+		assert.False(ok, "Expected %s not to be exact", raw)
+	}
+
+	v, _ := ParseVersion("Isabelle2024")
+	exact, ok := ExactConstraint(v).Exact()
+	assert.True(ok)
+	assert.Equal(0, exact.Compare(v))
+}
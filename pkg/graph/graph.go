@@ -0,0 +1,135 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var ErrCyclic = errors.New("graph contains cycles")
+
+// Graph is a directed graph, mapping each node to the nodes that it has an edge to. Nodes that are only the
+// target of edges do not need to be present as keys.
+type Graph map[string][]string
+
+// Nodes returns every node of the graph in sorted order, including those that are only the target of edges.
+func (g Graph) Nodes() []string {
+	nodes := make(map[string]bool, len(g))
+	for node, targets := range g {
+		nodes[node] = true
+		for _, target := range targets {
+			nodes[target] = true
+		}
+	}
+
+	return slices.Sorted(maps.Keys(nodes))
+}
+
+// StronglyConnectedComponents returns the strongly connected components of the graph, using Tarjan's algorithm.
+// Nodes are visited in sorted order, and the nodes of each component are sorted, so the result is deterministic.
+// Components are returned in reverse topological order - a component is returned before any component with an
+// edge to it.
+func (g Graph) StronglyConnectedComponents() [][]string {
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := make([]string, 0)
+	components := make([][]string, 0)
+
+	var connect func(node string)
+	connect = func(node string) {
+		index[node] = len(index)
+		lowLink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		targets := slices.Clone(g[node])
+		slices.Sort(targets)
+		for _, target := range targets {
+			if _, visited := index[target]; !visited {
+				connect(target)
+				lowLink[node] = min(lowLink[node], lowLink[target])
+			} else if onStack[target] {
+				lowLink[node] = min(lowLink[node], index[target])
+			}
+		}
+
+		if lowLink[node] != index[node] {
+			return
+		}
+
+		component := make([]string, 0, 1)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+
+			component = append(component, top)
+			if top == node {
+				break
+			}
+		}
+		slices.Sort(component)
+		components = append(components, component)
+	}
+
+	for _, node := range g.Nodes() {
+		if _, visited := index[node]; !visited {
+			connect(node)
+		}
+	}
+
+	return components
+}
+
+// Cycles returns the strongly connected components of the graph that contain a cycle - those with more than
+// one node, or a single node with an edge to itself. The cycles are ordered by their first node.
+func (g Graph) Cycles() [][]string {
+	cycles := make([][]string, 0)
+	for _, component := range g.StronglyConnectedComponents() {
+		if len(component) > 1 || slices.Contains(g[component[0]], component[0]) {
+			cycles = append(cycles, component)
+		}
+	}
+
+	slices.SortFunc(cycles, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	return cycles
+}
+
+// Levels groups the nodes of the graph into levels, such that every node only has edges to nodes within earlier
+// levels. The nodes of each level are sorted. If the graph contains any cycles then it cannot be ordered, and an
+// error wrapping ErrCyclic that lists the cycles is returned.
+func (g Graph) Levels() ([][]string, error) {
+	if cycles := g.Cycles(); len(cycles) > 0 {
+		descriptions := make([]string, 0, len(cycles))
+		for _, cycle := range cycles {
+			descriptions = append(descriptions, strings.Join(cycle, ", "))
+		}
+
+		return nil, fmt.Errorf("%w - %s", ErrCyclic, strings.Join(descriptions, "; "))
+	}
+
+	// the components are in reverse topological order, so the targets of a node's edges are always visited first
+	level := make(map[string]int)
+	levels := make([][]string, 0)
+	for _, component := range g.StronglyConnectedComponents() {
+		node := component[0]
+
+		for _, target := range g[node] {
+			level[node] = max(level[node], level[target]+1)
+		}
+
+		if level[node] == len(levels) {
+			levels = append(levels, make([]string, 0))
+		}
+		levels[level[node]] = append(levels[level[node]], node)
+	}
+
+	for _, nodes := range levels {
+		slices.Sort(nodes)
+	}
+
+	return levels, nil
+}
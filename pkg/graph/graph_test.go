@@ -0,0 +1,54 @@
+package graph
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	assert := asrt.New(t)
+
+	g := Graph{
+		"A": {"B"},
+		"B": {"C", "D"},
+		"C": {"A"},
+		"D": {"E"},
+	}
+
+	assert.Equal([][]string{{"E"}, {"D"}, {"A", "B", "C"}}, g.StronglyConnectedComponents())
+}
+
+func TestCycles(t *testing.T) {
+	assert := asrt.New(t)
+
+	g := Graph{
+		"A":    {"B"},
+		"B":    {"A"},
+		"C":    {"D"},
+		"D":    {"E"},
+		"E":    {"C"},
+		"F":    {"A"},
+		"Self": {"Self"},
+	}
+
+	assert.Equal([][]string{{"A", "B"}, {"C", "D", "E"}, {"Self"}}, g.Cycles())
+	assert.Empty(Graph{"A": {"B"}, "B": {"C"}}.Cycles())
+}
+
+func TestLevels(t *testing.T) {
+	assert := asrt.New(t)
+
+	g := Graph{
+		"App":  {"Lib", "Util"},
+		"Lib":  {"Base"},
+		"Util": {"Base"},
+		"Tool": {},
+	}
+
+	levels, err := g.Levels()
+	assert.NoError(err)
+	assert.Equal([][]string{{"Base", "Tool"}, {"Lib", "Util"}, {"App"}}, levels)
+
+	_, err = Graph{"A": {"B"}, "B": {"A"}}.Levels()
+	assert.ErrorIs(err, ErrCyclic)
+}
@@ -0,0 +1,80 @@
+package index
+
+import (
+	"github.com/pelletier/go-toml/v2"
+	"github.com/tandemdude/proofman/pkg/config"
+	"slices"
+	"strings"
+)
+
+// FileName is the name of the aggregate index file written to the root of each index repository branch.
+const FileName = "index.toml"
+
+// Entry describes a single package within the aggregate index file.
+type Entry struct {
+	Name        string               `toml:"name"`
+	Version     config.Version       `toml:"version"`
+	Description string               `toml:"description"`
+	Topics      []string             `toml:"topics,omitempty"`
+	Checksum    string               `toml:"checksum,omitempty"`
+	Sessions    []string             `toml:"sessions"`
+	Requires    []config.Requirement `toml:"requires"`
+}
+
+// File is the aggregate index file, which summarises every package indexed for a single AFP version so
+// that consumers do not need to read the manifest of each package individually.
+type File struct {
+	Version  config.Version `toml:"version"`
+	Packages []Entry        `toml:"package"`
+}
+
+// NewFile creates the aggregate index file for the given manifests, all of which must belong to the given version.
+func NewFile(version config.Version, manifests []*config.ProofmanConfig) *File {
+	file := &File{Version: version, Packages: make([]Entry, 0, len(manifests))}
+
+	for _, manifest := range manifests {
+		entry := Entry{
+			Name:        manifest.Project.Name,
+			Version:     version,
+			Description: manifest.Project.Description,
+			Topics:      manifest.Project.Topics,
+			Checksum:    manifest.Checksum,
+			Sessions:    make([]string, 0, len(manifest.Sessions)),
+			Requires:    manifest.Project.Requires,
+		}
+		for _, session := range manifest.Sessions {
+			entry.Sessions = append(entry.Sessions, session.Name)
+		}
+
+		file.Packages = append(file.Packages, entry)
+	}
+	slices.SortFunc(file.Packages, func(a, b Entry) int { return strings.Compare(a.Name, b.Name) })
+
+	return file
+}
+
+// ParseFile parses the contents of an aggregate index file.
+func ParseFile(content []byte) (*File, error) {
+	file := &File{}
+	if err := toml.Unmarshal(content, file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Marshal serialises the aggregate index file.
+func (f *File) Marshal() ([]byte, error) {
+	return toml.Marshal(f)
+}
+
+// Entry returns the entry of the named package, or nil if the package is not within the file.
+func (f *File) Entry(name string) *Entry {
+	for i := range f.Packages {
+		if f.Packages[i].Name == name {
+			return &f.Packages[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package index
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/pkg/config"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	assert := asrt.New(t)
+
+	version, err := config.ParseVersion("2024-01-01")
+	assert.NoError(err)
+	requires := []config.Requirement{{Name: "A", Constraint: config.ExactConstraint(version)}}
+
+	file := NewFile(version, []*config.ProofmanConfig{
+		{
+			Checksum: "b",
+			Project:  config.Project{Name: "B", Description: "The B entry", Topics: []string{"Logic"}, Requires: requires},
+			Sessions: []config.Session{{Name: "B"}, {Name: "B_Examples", Parent: "B"}},
+		},
+		{
+			Checksum: "a",
+			Project:  config.Project{Name: "A", Description: "The A entry", Requires: []config.Requirement{}},
+			Sessions: []config.Session{{Name: "A"}},
+		},
+	})
+
+	// entries are sorted by name, and every entry is made for the version of the file
+	assert.Equal(&File{Version: version, Packages: []Entry{
+		{
+			Name:        "A",
+			Version:     version,
+			Description: "The A entry",
+			Checksum:    "a",
+			Sessions:    []string{"A"},
+			Requires:    []config.Requirement{},
+		},
+		{
+			Name:        "B",
+			Version:     version,
+			Description: "The B entry",
+			Topics:      []string{"Logic"},
+			Checksum:    "b",
+			Sessions:    []string{"B", "B_Examples"},
+			Requires:    requires,
+		},
+	}}, file)
+
+	assert.Equal("B", file.Entry("B").Name)
+	assert.Nil(file.Entry("Missing"))
+
+	content, err := file.Marshal()
+	assert.NoError(err)
+	parsed, err := ParseFile(content)
+	assert.NoError(err)
+	assert.Equal(file, parsed)
+
+	_, err = ParseFile([]byte("version = ["))
+	assert.Error(err)
+}
@@ -0,0 +1,377 @@
+package index
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"github.com/hashicorp/go-set/v3"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/indexer/git"
+	"github.com/tandemdude/proofman/pkg/localcache"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+// theoriesDirName is the directory within the index repository that contains one
+// directory per indexed package, mirroring the layout of the AFP checkout it was created from
+const theoriesDirName = "thys"
+
+var (
+	ErrNoRepositoryUrl       = errors.New("no index repository URL configured - pass --index-url or set PROOFMAN_INDEX_URL")
+	ErrCannotCloneRepository = errors.New("failed cloning index repository")
+	ErrCannotFetchRepository = errors.New("failed fetching index repository")
+	ErrNotCached             = errors.New("index repository has not been cloned yet")
+	ErrNoVersions            = errors.New("index repository does not contain any indexed versions")
+	ErrUnknownVersion        = errors.New("version not present in index repository")
+	ErrUnknownCommit         = errors.New("commit not present in index repository")
+	ErrUnknownPackage        = errors.New("package not present in index repository")
+	ErrCannotReadManifest    = errors.New("failed reading package manifest from index repository")
+	ErrNoIndexFile           = errors.New("version does not contain an aggregate index file")
+	ErrCannotReadIndexFile   = errors.New("failed reading aggregate index file from index repository")
+)
+
+// Repository is a local clone of the index repository created by the 'index-afp' command. Each branch
+// of the repository holds the packages of a single AFP version.
+type Repository struct {
+	path string
+
+	packages  map[config.Version]*set.Set[string]
+	manifests map[string]*config.ProofmanConfig
+	files     map[config.Version]*File
+}
+
+func newRepository(repoPath string) *Repository {
+	return &Repository{
+		path:      repoPath,
+		packages:  make(map[config.Version]*set.Set[string]),
+		manifests: make(map[string]*config.ProofmanConfig),
+		files:     make(map[config.Version]*File),
+	}
+}
+
+// cachePath returns the path of the local clone of the index repository at the given URL, and whether
+// the clone exists.
+func cachePath(url string) (string, bool, error) {
+	if url == "" {
+		return "", false, ErrNoRepositoryUrl
+	}
+
+	// a separate clone is kept for each index URL so that switching between indexes is harmless
+	digest := sha1.Sum([]byte(url))
+	repoPath, err := localcache.Path(filepath.Join("index", hex.EncodeToString(digest[:])[:12]))
+	if err != nil {
+		return "", false, err
+	}
+
+	exists, err := internal.PathExists(filepath.Join(repoPath, ".git"))
+	if err != nil {
+		return "", false, err
+	}
+
+	return repoPath, exists, nil
+}
+
+// OpenCached opens the existing local clone of the index repository at the given URL without fetching any
+// new changes, so it does not require network access. If there is no local clone then ErrNotCached is returned.
+func OpenCached(url string) (*Repository, error) {
+	repoPath, exists, err := cachePath(url)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotCached
+	}
+
+	return newRepository(repoPath), nil
+}
+
+// Open clones the index repository at the given URL into the local cache, or fetches any new
+// changes if a clone already exists.
+func Open(url string) (*Repository, error) {
+	repoPath, exists, err := cachePath(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		logging.Verbose("fetching index repository changes into %s", repoPath)
+		if err = git.Fetch(repoPath); err != nil {
+			return nil, errors.Join(err, ErrCannotFetchRepository)
+		}
+	} else {
+		logging.Unquiet("cloning index repository %s", url)
+		if err = git.Clone(url, repoPath); err != nil {
+			return nil, errors.Join(err, ErrCannotCloneRepository)
+		}
+	}
+
+	return newRepository(repoPath), nil
+}
+
+func ref(version config.Version) string {
+	return "origin/" + version.String()
+}
+
+// Versions returns all the AFP versions that have been indexed, in ascending order.
+func (r *Repository) Versions() ([]config.Version, error) {
+	branches, err := git.RemoteBranches(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]config.Version, 0, len(branches))
+	for _, branch := range branches {
+		// any other branches, such as the default branch, do not contain indexed packages
+		version, err := config.ParseVersion(branch)
+		if err != nil {
+			logging.Verbose("ignoring non-version index repository branch %s", branch)
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+	slices.SortFunc(versions, config.Version.Compare)
+
+	return versions, nil
+}
+
+// Latest returns the newest indexed AFP version.
+func (r *Repository) Latest() (config.Version, error) {
+	return r.Newest(config.Constraint{})
+}
+
+// Newest returns the newest indexed AFP version that satisfies the given constraint.
+func (r *Repository) Newest(constraint config.Constraint) (config.Version, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return config.Version{}, err
+	}
+	if len(versions) == 0 {
+		return config.Version{}, ErrNoVersions
+	}
+
+	newest, ok := constraint.Newest(versions)
+	if !ok {
+		return config.Version{}, errors.Join(ErrUnknownVersion, errors.New("no indexed version matches "+constraint.String()))
+	}
+
+	return newest, nil
+}
+
+// IndexFile reads the aggregate index file of the given version's branch. Versions indexed before the aggregate
+// index file was introduced do not have one, in which case ErrNoIndexFile is returned.
+func (r *Repository) IndexFile(version config.Version) (*File, error) {
+	if cached, ok := r.files[version]; ok {
+		if cached == nil {
+			return nil, ErrNoIndexFile
+		}
+		return cached, nil
+	}
+
+	if _, err := r.Commit(version); err != nil {
+		return nil, err
+	}
+
+	content, err := git.Show(r.path, ref(version), FileName)
+	if err != nil {
+		r.files[version] = nil
+		return nil, ErrNoIndexFile
+	}
+
+	file, err := ParseFile([]byte(content))
+	if err != nil {
+		return nil, errors.Join(err, ErrCannotReadIndexFile)
+	}
+	r.files[version] = file
+
+	return file, nil
+}
+
+// Entries returns the aggregate index entries of every package indexed for the given version. Versions without
+// an aggregate index file have their entries built from the individual package manifests instead.
+func (r *Repository) Entries(version config.Version) ([]Entry, error) {
+	file, err := r.IndexFile(version)
+	if err == nil {
+		return file.Packages, nil
+	}
+	if !errors.Is(err, ErrNoIndexFile) {
+		return nil, err
+	}
+
+	packages, err := r.Packages(version)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]*config.ProofmanConfig, 0, packages.Size())
+	for pkg := range packages.Items() {
+		manifest, err := r.Manifest(version, pkg)
+		if errors.Is(err, ErrUnknownPackage) {
+			// the directory does not contain an indexed package
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		manifests = append(manifests, manifest)
+	}
+
+	return NewFile(version, manifests).Packages, nil
+}
+
+// Packages returns the names of all the packages indexed for the given version.
+func (r *Repository) Packages(version config.Version) (*set.Set[string], error) {
+	if cached, ok := r.packages[version]; ok {
+		return cached, nil
+	}
+
+	file, err := r.IndexFile(version)
+	if err == nil {
+		packages := set.New[string](len(file.Packages))
+		for _, entry := range file.Packages {
+			packages.Insert(entry.Name)
+		}
+		r.packages[version] = packages
+
+		return packages, nil
+	}
+	if !errors.Is(err, ErrNoIndexFile) {
+		return nil, err
+	}
+
+	// fall back to listing the package directories
+	dirs, err := git.ListDirectories(r.path, ref(version), theoriesDirName)
+	if err != nil {
+		return nil, errors.Join(err, ErrUnknownVersion, errors.New(version.String()))
+	}
+
+	packages := set.From(dirs)
+	r.packages[version] = packages
+
+	return packages, nil
+}
+
+// PackageVersions returns the indexed versions that contain the given package, in ascending order.
+func (r *Repository) PackageVersions(pkg string) ([]config.Version, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]config.Version, 0)
+	for _, version := range versions {
+		packages, err := r.Packages(version)
+		if err != nil {
+			return nil, err
+		}
+
+		if packages.Contains(pkg) {
+			found = append(found, version)
+		}
+	}
+
+	return found, nil
+}
+
+// SessionPackages returns a map of session name to the name of the package that provides it, for every session
+// provided by the packages indexed for the given version.
+func (r *Repository) SessionPackages(version config.Version) (map[string]string, error) {
+	file, err := r.IndexFile(version)
+	if err == nil {
+		sessions := make(map[string]string)
+		for _, entry := range file.Packages {
+			for _, session := range entry.Sessions {
+				sessions[session] = entry.Name
+			}
+		}
+
+		return sessions, nil
+	}
+	if !errors.Is(err, ErrNoIndexFile) {
+		return nil, err
+	}
+
+	packages, err := r.Packages(version)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make(map[string]string)
+	for pkg := range packages.Items() {
+		manifest, err := r.Manifest(version, pkg)
+		if errors.Is(err, ErrUnknownPackage) {
+			// the directory does not contain an indexed package
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, session := range manifest.Sessions {
+			sessions[session.Name] = pkg
+		}
+	}
+
+	return sessions, nil
+}
+
+// Commit returns the commit hash that the given version's branch currently points to.
+func (r *Repository) Commit(version config.Version) (string, error) {
+	commit, err := git.RevParse(r.path, ref(version))
+	if err != nil {
+		return "", errors.Join(err, ErrUnknownVersion, errors.New(version.String()))
+	}
+
+	return commit, nil
+}
+
+// Manifest reads the proofman.toml file of a package from the given version's branch, without
+// needing to check the branch out.
+func (r *Repository) Manifest(version config.Version, pkg string) (*config.ProofmanConfig, error) {
+	key := version.String() + "/" + pkg
+	if cached, ok := r.manifests[key]; ok {
+		return cached, nil
+	}
+
+	content, err := git.Show(r.path, ref(version), path.Join(theoriesDirName, pkg, internal.ConfigFileName))
+	if err != nil {
+		return nil, errors.Join(err, ErrUnknownPackage, errors.New(pkg+" @ "+version.String()))
+	}
+
+	cfg := &config.ProofmanConfig{}
+	if err = toml.Unmarshal([]byte(content), cfg); err != nil {
+		return nil, errors.Join(err, ErrCannotReadManifest)
+	}
+	r.manifests[key] = cfg
+
+	return cfg, nil
+}
+
+// Checkout switches the working tree of the local clone to the given version's branch, so that
+// the package files can be copied out of it using PackagePath.
+func (r *Repository) Checkout(version config.Version) error {
+	if err := git.Checkout(r.path, ref(version)); err != nil {
+		return errors.Join(err, ErrUnknownVersion, errors.New(version.String()))
+	}
+
+	return nil
+}
+
+// CheckoutCommit switches the working tree of the local clone to the given commit.
+func (r *Repository) CheckoutCommit(commit string) error {
+	if err := git.Checkout(r.path, commit); err != nil {
+		return errors.Join(err, ErrUnknownCommit, errors.New(commit))
+	}
+
+	return nil
+}
+
+// PackagePath returns the path to the given package within the working tree of the local clone.
+func (r *Repository) PackagePath(pkg string) string {
+	return filepath.Join(r.path, theoriesDirName, pkg)
+}
@@ -0,0 +1,88 @@
+package index
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Match is a package entry that matched a search query, alongside the score used to rank it.
+type Match struct {
+	Entry
+	Score int
+}
+
+// termScore scores how well a single lowercase search term matches the entry. Matches against the package name
+// are ranked above matches against session names, which are ranked above matches against topics and the description.
+func termScore(entry *Entry, term string) int {
+	name := strings.ToLower(entry.Name)
+	switch {
+	case name == term:
+		return 100
+	case strings.HasPrefix(name, term):
+		return 60
+	case strings.Contains(name, term):
+		return 40
+	}
+
+	best := 0
+	for _, session := range entry.Sessions {
+		session = strings.ToLower(session)
+		if session == term {
+			best = max(best, 50)
+		} else if strings.Contains(session, term) {
+			best = max(best, 25)
+		}
+	}
+
+	for _, topic := range entry.Topics {
+		if strings.Contains(strings.ToLower(topic), term) {
+			best = max(best, 15)
+		}
+	}
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(entry.Description), func(r rune) bool {
+		return !(r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r > 127)
+	}) {
+		if word == term {
+			best = max(best, 10)
+		} else if strings.HasPrefix(word, term) {
+			best = max(best, 5)
+		}
+	}
+
+	return best
+}
+
+// Search returns the entries that match every whitespace separated term of the query, ordered from the best
+// match to the worst. Terms are matched case-insensitively against the package name, session names, topics
+// and the words of the description.
+func Search(entries []Entry, query string) []Match {
+	terms := strings.Fields(strings.ToLower(query))
+
+	matches := make([]Match, 0)
+	for i := range entries {
+		score := 0
+		for _, term := range terms {
+			s := termScore(&entries[i], term)
+			if s == 0 {
+				score = 0
+				break
+			}
+			score += s
+		}
+
+		if score > 0 {
+			matches = append(matches, Match{Entry: entries[i], Score: score})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return matches
+}
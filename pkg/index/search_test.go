@@ -0,0 +1,40 @@
+package index
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func matchNames(matches []Match) []string {
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, m.Name)
+	}
+
+	return names
+}
+
+func TestSearchRanksNameAboveSessionAndDescription(t *testing.T) {
+	assert := asrt.New(t)
+
+	entries := []Entry{
+		{Name: "Graph_Theory", Description: "Graph theory basics", Sessions: []string{"Graph_Theory"}},
+		{Name: "Dijkstra", Description: "Verified shortest paths in a graph", Topics: []string{"Computer science/Algorithms/Graph"}},
+		{Name: "Flows", Description: "Network flows", Sessions: []string{"Flows", "Graph"}},
+		{Name: "Unrelated", Description: "Nothing to see here"},
+	}
+
+	assert.Equal([]string{"Graph_Theory", "Flows", "Dijkstra"}, matchNames(Search(entries, "graph")))
+}
+
+func TestSearchRequiresEveryTerm(t *testing.T) {
+	assert := asrt.New(t)
+
+	entries := []Entry{
+		{Name: "Multiset_Ordering", Description: "Multiset ordering for term rewriting"},
+		{Name: "Multisets", Description: "Basic multisets"},
+	}
+
+	assert.Equal([]string{"Multiset_Ordering"}, matchNames(Search(entries, "MULTISET rewriting")))
+	assert.Empty(Search(entries, "lattice"))
+}
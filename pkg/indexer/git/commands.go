@@ -57,6 +57,29 @@ func SetupRemote(remoteUrl, directory string) error {
 	return nil
 }
 
+// SetupRemoteFromBranch prepares the directory to commit on top of the given branch of the remote. Unlike
+// SetupRemote the working tree is left untouched, so that the next commit only contains the differences
+// between the directory and the branch.
+func SetupRemoteFromBranch(remoteUrl, directory, branch string) error {
+	_, err := runGitCommandInDirectory(directory, "init")
+	if err != nil {
+		return err
+	}
+
+	_, err = runGitCommandInDirectory(directory, "remote", "add", "origin", remoteUrl)
+	if err != nil {
+		return err
+	}
+
+	_, err = runGitCommandInDirectory(directory, "fetch", "origin", branch)
+	if err != nil {
+		return err
+	}
+
+	_, err = runGitCommandInDirectory(directory, "reset", "--mixed", "origin/"+branch)
+	return err
+}
+
 func MakeBranch(inDirectory string, name string) error {
 	_, err := runGitCommandInDirectory(inDirectory, "checkout", "-b", name)
 	return err
@@ -76,3 +99,62 @@ func Push(inDirectory string) error {
 	_, err := runGitCommandInDirectory(inDirectory, "push", "-u", "origin", "HEAD")
 	return err
 }
+
+func Clone(remoteUrl, directory string) error {
+	_, err := runGitCommand("clone", remoteUrl, directory)
+	return err
+}
+
+func Fetch(inDirectory string) error {
+	_, err := runGitCommandInDirectory(inDirectory, "fetch", "--prune", "origin")
+	return err
+}
+
+func Checkout(inDirectory string, ref string) error {
+	_, err := runGitCommandInDirectory(inDirectory, "checkout", "--force", "--detach", ref)
+	return err
+}
+
+func RevParse(inDirectory string, ref string) (string, error) {
+	return runGitCommandInDirectory(inDirectory, "rev-parse", ref)
+}
+
+func RemoteBranches(inDirectory string) ([]string, error) {
+	out, err := runGitCommandInDirectory(inDirectory, "for-each-ref", "--format=%(refname:lstrip=3)", "refs/remotes/origin")
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make([]string, 0)
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "HEAD" {
+			continue
+		}
+
+		branches = append(branches, line)
+	}
+
+	return branches, nil
+}
+
+func Show(inDirectory string, ref string, path string) (string, error) {
+	return runGitCommandInDirectory(inDirectory, "show", ref+":"+path)
+}
+
+func ListDirectories(inDirectory string, ref string, path string) ([]string, error) {
+	out, err := runGitCommandInDirectory(inDirectory, "ls-tree", "-d", "--name-only", ref+":"+path)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make([]string, 0)
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+
+	return dirs, nil
+}
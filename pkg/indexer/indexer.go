@@ -9,14 +9,20 @@ import (
 	"github.com/schollz/progressbar/v3"
 	"github.com/tandemdude/proofman/internal"
 	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/checksum"
 	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/graph"
+	"github.com/tandemdude/proofman/pkg/index"
 	"github.com/tandemdude/proofman/pkg/indexer/git"
 	"github.com/tandemdude/proofman/pkg/isabelle"
 	"github.com/tandemdude/proofman/pkg/parser"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 )
 
 var versionRegex = regexp.MustCompile(`(?m)^VERSION=(\d{4}(?:-\d+)*)$`)
@@ -28,22 +34,44 @@ var (
 	ErrCannotReadROOT               = errors.New("failed reading ROOT file")
 	ErrCannotParseROOT              = errors.New("failed parsing ROOT file")
 	ErrCannotReadROOTS              = errors.New("failed reading ROOTS file")
+	ErrCannotReadTheory             = errors.New("failed reading theory file")
 	ErrUnknownSession               = errors.New("unknown session required by package")
 	ErrCannotSetupIndexRepo         = errors.New("failed setting up index repository")
 	ErrCannotMakeBranch             = errors.New("cannot make new index repository branch")
 	ErrCannotPopulateRepo           = errors.New("failed populating index repository with new files")
+	ErrCannotReadPreviousIndex      = errors.New("failed reading previous version from index repository")
+	ErrCannotHashPackage            = errors.New("failed hashing theory package")
+	ErrCyclicDependencies           = errors.New("cyclic dependencies between packages")
 )
 
+// Options configures how the AFP is indexed.
+type Options struct {
+	// Jobs is the number of packages that are resolved concurrently. Values less than one are treated as one.
+	Jobs int
+	// DryRun prints the manifests that would be written instead of writing them. Nothing is modified on disk.
+	DryRun bool
+	// OutputDirectory, if set, is the directory that the finished index tree is written to instead of the
+	// index repository. The AFP directory is left untouched and no git commands are run.
+	OutputDirectory string
+	// AllowCycles reports cyclic dependencies between packages as warnings, instead of failing the index.
+	AllowCycles bool
+	// Full regenerates the manifest of every package, instead of only those that have changed since the
+	// previous version within the index repository. The previous version can only be read if the index repository
+	// is known, so a dry run or an output directory without one always regenerates every manifest.
+	Full bool
+}
+
 type AFPIndexer struct {
-	afpVersion         string
+	afpVersion         config.Version
 	afpDirectoryPath   string
 	indexRepositoryUrl string
+	options            Options
 }
 
-func New(afpDirectoryPath, indexRepositoryUrl, versionOverride string) (*AFPIndexer, error) {
-	version := versionOverride
+func New(afpDirectoryPath, indexRepositoryUrl, versionOverride string, options Options) (*AFPIndexer, error) {
+	rawVersion := versionOverride
 
-	if version == "" {
+	if rawVersion == "" {
 		// check the directory exists, and try to read the AFP version from /etc/version
 		// if we can't resolve the version or the directory then fail (we won't be able to index it)
 		content, err := os.ReadFile(filepath.Join(afpDirectoryPath, "etc", "version"))
@@ -55,7 +83,12 @@ func New(afpDirectoryPath, indexRepositoryUrl, versionOverride string) (*AFPInde
 		if match == nil {
 			return nil, ErrCannotParseVersion
 		}
-		version = string(match[1])
+		rawVersion = string(match[1])
+	}
+
+	version, err := config.ParseVersion(rawVersion)
+	if err != nil {
+		return nil, errors.Join(err, ErrCannotParseVersion)
 	}
 	logging.Unquiet("AFP directory matches version: %s", version)
 
@@ -63,17 +96,48 @@ func New(afpDirectoryPath, indexRepositoryUrl, versionOverride string) (*AFPInde
 		afpVersion:         version,
 		afpDirectoryPath:   afpDirectoryPath,
 		indexRepositoryUrl: indexRepositoryUrl,
+		options:            options,
 	}, nil
 }
 
+// theoriesDirName is the directory containing the theory packages, both within the AFP and the index repository.
+const theoriesDirName = "thys"
+
 func (a *AFPIndexer) theoriesPath() string {
-	return filepath.Join(a.afpDirectoryPath, "thys")
+	return filepath.Join(a.afpDirectoryPath, theoriesDirName)
 }
 
 type manifest struct {
-	Name             string
+	Name string
+	// Checksum is the hash of the package contents, see config.ProofmanConfig
+	Checksum         string
 	ProvidesSessions *set.Set[string]
 	RequiresSessions *set.Set[string]
+	// ImportedSessions are the required sessions that are only referenced by qualified theory imports, and are
+	// not listed within the ROOT file
+	ImportedSessions *set.Set[string]
+	Sessions         []config.Session
+	// Disagreements describe where the sessions listed within the ROOT file differ from those imported by theories
+	Disagreements []string
+	// Diagnostics are the errors within the ROOT file, for which the affected sessions were skipped
+	Diagnostics []*parser.Diagnostic
+}
+
+// manifestFromConfig rebuilds the manifest of a package from the config written for it by a previous index, so
+// that packages which have not changed do not need their ROOT and theory files parsing again.
+func manifestFromConfig(name string, cfg *config.ProofmanConfig, builtinSessions []string) *manifest {
+	m := &manifest{
+		Name:             name,
+		Checksum:         cfg.Checksum,
+		ProvidesSessions: set.From(cfg.ProvidedSessions()),
+		RequiresSessions: set.From(cfg.RequiredSessions()),
+		ImportedSessions: set.From(cfg.ImportedSessions()),
+		Sessions:         cfg.Sessions,
+	}
+	// parent sessions are recorded as written within the ROOT file, so may be builtin sessions
+	m.RequiresSessions.RemoveSlice(builtinSessions)
+
+	return m
 }
 
 func (a *AFPIndexer) resolveManifest(thy string, builtinSessions []string) (*manifest, error) {
@@ -83,51 +147,483 @@ func (a *AFPIndexer) resolveManifest(thy string, builtinSessions []string) (*man
 		Name:             thy,
 		ProvidesSessions: set.New[string](0),
 		RequiresSessions: set.New[string](0),
+		ImportedSessions: set.New[string](0),
+		Sessions:         make([]config.Session, 0),
 	}
 
-	rootFile, err := os.ReadFile(filepath.Join(a.theoriesPath(), thy, "ROOT"))
+	rootPath := filepath.Join(a.theoriesPath(), thy, "ROOT")
+	rootFile, err := os.ReadFile(rootPath)
 	if err != nil {
 		return nil, errors.Join(err, ErrCannotReadROOT)
 	}
 
 	// resolving the sessions provided by this package is easy, we just
 	// need to parse the ROOT file and take all session definitions
-	parsed, err := parser.ParseRootFile(bytes.NewReader(rootFile))
+	parsed, diagnostics, err := parser.ParseRootFileRecovering(rootPath, bytes.NewReader(rootFile))
 	if err != nil {
-		return nil, errors.Join(err, ErrCannotParseROOT)
+		return nil, errors.Join(err, ErrCannotReadROOT)
+	}
+
+	// sessions containing errors are skipped, the package only fails if none of its sessions could be parsed
+	if len(diagnostics) > 0 {
+		sessions := 0
+		for _, chapter := range parsed.Chapters {
+			sessions += len(chapter.Sessions)
+		}
+
+		if sessions == 0 {
+			errs := make([]error, 0, len(diagnostics)+1)
+			for _, diagnostic := range diagnostics {
+				errs = append(errs, diagnostic)
+			}
+			return nil, errors.Join(append(errs, ErrCannotParseROOT)...)
+		}
+
+		pkgManifest.Diagnostics = diagnostics
 	}
 
 	// most - if not all - AFP packages specify their sessions within the "AFP" chapter, but just in case
 	// I am going to check all the chapters
-	for _, chapter := range parsed.Chapters {
-		for _, session := range chapter.Sessions {
+	for _, chapterName := range parsed.ChapterOrder {
+		for _, session := range parsed.Chapters[chapterName].Sessions {
 			pkgManifest.ProvidesSessions.Insert(session.Name)
 
-			// required sessions are those that are explicitly mentioned within the ROOT file (sessions block)
-			// it is also possible to import sessions using the theory files, but I'm not sure if those also
-			// need to be mentioned in the ROOT - for now I am assuming they do (so I don't have to parse all the .thy files)
+			// required sessions are those that are explicitly mentioned within the ROOT file (sessions block), and
+			// those that are referenced by qualified imports within the theory files of the session
 			pkgManifest.RequiresSessions.InsertSlice(session.Sessions)
+			// the parent session is required too - builtin and local parents are removed below
+			if session.SystemName != "" {
+				pkgManifest.RequiresSessions.Insert(session.SystemName)
+			}
+
+			sessionRequires := make([]string, 0, len(session.Sessions))
+			for _, s := range session.Sessions {
+				if !slices.Contains(builtinSessions, s) {
+					sessionRequires = append(sessionRequires, s)
+				}
+			}
+
+			imports, err := isabelle.SessionImports(filepath.Join(a.theoriesPath(), thy), session)
+			if err != nil {
+				return nil, errors.Join(err, ErrCannotReadTheory)
+			}
+
+			imported := set.New[string](0)
+			for _, imp := range imports {
+				if imp.Session == session.Name || slices.Contains(builtinSessions, imp.Session) || !imported.Insert(imp.Session) {
+					continue
+				}
+
+				if imp.Session != session.SystemName && !slices.Contains(session.Sessions, imp.Session) {
+					pkgManifest.Disagreements = append(pkgManifest.Disagreements, fmt.Sprintf(
+						"session %s: theory %s imports %s, but %s is not listed in the ROOT sessions",
+						session.Name, imp.Theory, imp.Import, imp.Session,
+					))
+					pkgManifest.ImportedSessions.Insert(imp.Session)
+				}
+			}
+			for _, s := range sessionRequires {
+				if !imported.Contains(s) {
+					pkgManifest.Disagreements = append(pkgManifest.Disagreements, fmt.Sprintf(
+						"session %s: %s is listed in the ROOT sessions, but no theory imports from it",
+						session.Name, s,
+					))
+				}
+			}
+
+			pkgManifest.Sessions = append(pkgManifest.Sessions, config.Session{
+				Name:     session.Name,
+				Parent:   session.SystemName,
+				Requires: sessionRequires,
+				Imports:  slices.Sorted(imported.Items()),
+			})
 		}
 	}
 
+	pkgManifest.RequiresSessions.InsertSet(pkgManifest.ImportedSessions)
 	// remove any Isabelle builtin sessions
 	pkgManifest.RequiresSessions.RemoveSlice(builtinSessions)
 	// remove any sessions that are provided by this package
 	pkgManifest.RequiresSessions.RemoveSet(pkgManifest.ProvidesSessions)
+	pkgManifest.ImportedSessions.RemoveSet(pkgManifest.ProvidesSessions)
 
 	logging.Verbose("theory package %s - provides %d, requires %d", thy, pkgManifest.ProvidesSessions.Size(), pkgManifest.RequiresSessions.Size())
 
 	return pkgManifest, nil
 }
 
-func (a *AFPIndexer) prepareIndexRepo() error {
-	err := git.SetupRemote(a.indexRepositoryUrl, a.afpDirectoryPath)
+// resolvePackage hashes the package and resolves its manifest, reusing the previous manifest if the package
+// contents have not changed.
+func (a *AFPIndexer) resolvePackage(thy string, builtinSessions []string, previous *config.ProofmanConfig) (*manifest, error) {
+	hash, err := checksum.Directory(filepath.Join(a.theoriesPath(), thy), internal.ConfigFileName)
+	if err != nil {
+		return nil, errors.Join(err, ErrCannotHashPackage)
+	}
+
+	if previous != nil && previous.Checksum == hash {
+		logging.Verbose("theory package %s is unchanged - reusing previous manifest", thy)
+		return manifestFromConfig(thy, previous, builtinSessions), nil
+	}
+
+	m, err := a.resolveManifest(thy, builtinSessions)
+	if err != nil {
+		return nil, err
+	}
+
+	// the manifest of a package with skipped sessions is not reused, as the sessions it skipped are not recorded
+	if len(m.Diagnostics) == 0 {
+		m.Checksum = hash
+	}
+	return m, nil
+}
+
+// skipDependentSessions removes the sessions that depend - directly or transitively - on a session that was skipped
+// because of errors within its ROOT file, as they cannot be built without it. Packages left without any sessions are
+// removed entirely. A description of each removed session is returned, so that it can be reported.
+func skipDependentSessions(manifests map[string]*manifest, builtinSessions []string) []string {
+	skipped := set.New[string](0)
+	for _, m := range manifests {
+		for _, diagnostic := range m.Diagnostics {
+			if diagnostic.Session != "" {
+				skipped.Insert(diagnostic.Session)
+			}
+		}
+	}
+
+	descriptions := make([]string, 0)
+	for changed := true; changed; {
+		changed = false
+
+		for _, name := range slices.Sorted(maps.Keys(manifests)) {
+			m := manifests[name]
+
+			kept := make([]config.Session, 0, len(m.Sessions))
+			for _, session := range m.Sessions {
+				dependencies := slices.Concat([]string{session.Parent}, session.Requires, session.Imports)
+				idx := slices.IndexFunc(dependencies, skipped.Contains)
+				if idx == -1 {
+					kept = append(kept, session)
+					continue
+				}
+
+				descriptions = append(descriptions, fmt.Sprintf(
+					"%s: session %s requires %s, which was skipped", name, session.Name, dependencies[idx],
+				))
+				skipped.Insert(session.Name)
+				changed = true
+			}
+
+			if len(kept) == len(m.Sessions) {
+				continue
+			}
+			if len(kept) == 0 {
+				descriptions = append(descriptions, fmt.Sprintf("%s: no sessions remain - skipping package", name))
+				delete(manifests, name)
+				continue
+			}
+
+			// the checksum is dropped so that the package is parsed again by the next index, see resolvePackage
+			rebuilt := manifestFromConfig(name, &config.ProofmanConfig{Sessions: kept}, builtinSessions)
+			rebuilt.Disagreements, rebuilt.Diagnostics = m.Disagreements, m.Diagnostics
+			manifests[name] = rebuilt
+		}
+	}
+
+	return descriptions
+}
+
+// resolveManifests resolves the manifests of all the given packages, using up to Options.Jobs goroutines. Packages
+// whose checksum matches their previous manifest reuse it instead of being parsed again. The errors of all packages
+// that could not be resolved are joined and returned together.
+func (a *AFPIndexer) resolveManifests(
+	packages []string,
+	builtinSessions []string,
+	previous map[string]*config.ProofmanConfig,
+	pbar *progressbar.ProgressBar,
+) (map[string]*manifest, error) {
+	jobs := max(a.options.Jobs, 1)
+
+	// each worker only writes to the indices of the packages it takes, so the results do not depend on scheduling
+	results := make([]*manifest, len(packages))
+	errs := make([]error, len(packages))
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, len(packages)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				results[i], errs[i] = a.resolvePackage(packages[i], builtinSessions, previous[packages[i]])
+				if errs[i] != nil {
+					errs[i] = fmt.Errorf("%s: %w", packages[i], errs[i])
+				}
+
+				_ = pbar.Add(1)
+			}
+		}()
+	}
+
+	for i := range packages {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	manifests := make(map[string]*manifest, len(results))
+	for _, m := range results {
+		manifests[m.Name] = m
+	}
+
+	return manifests, nil
+}
+
+// writeIfChanged writes the content to the file at the given path, unless the file already has exactly that
+// content. It returns whether the file was written.
+func writeIfChanged(path string, content []byte) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, content) {
+		return false, nil
+	}
+
+	if err = os.WriteFile(path, content, 0666); err != nil {
+		return false, errors.Join(err, ErrCannotPopulateRepo)
+	}
+
+	return true, nil
+}
+
+// writeOutputDirectory copies each of the packages, alongside its manifest, into the output directory using the
+// same layout as the index repository. Packages whose manifest within the output directory is already up-to-date
+// are left untouched - the manifest contains the checksum of the package, so the package has not changed either.
+// The number of packages that were written is returned.
+func (a *AFPIndexer) writeOutputDirectory(packages []string, configs map[string][]byte, pbar *progressbar.ProgressBar) (int, error) {
+	pbar.Reset()
+	pbar.Describe("copying")
+
+	written := 0
+	for _, pkgName := range packages {
+		destination := filepath.Join(a.options.OutputDirectory, theoriesDirName, pkgName)
+		manifestPath := filepath.Join(destination, internal.ConfigFileName)
+
+		if existing, err := os.ReadFile(manifestPath); err == nil && bytes.Equal(existing, configs[pkgName]) {
+			logging.Verbose("%s is unchanged within the output directory - skipping", pkgName)
+			_ = pbar.Add(1)
+			continue
+		}
+
+		logging.Verbose("copying %s into the output directory", pkgName)
+
+		if err := os.RemoveAll(destination); err != nil {
+			return written, errors.Join(err, ErrCannotPopulateRepo)
+		}
+		if err := internal.CopyDirectory(filepath.Join(a.theoriesPath(), pkgName), destination); err != nil {
+			return written, errors.Join(err, ErrCannotPopulateRepo)
+		}
+		if err := os.WriteFile(manifestPath, configs[pkgName], 0666); err != nil {
+			return written, errors.Join(err, ErrCannotPopulateRepo)
+		}
+		written++
+
+		_ = pbar.Add(1)
+	}
+
+	return written, nil
+}
+
+// previousIndex returns the newest version within the index repository that is older than the version being
+// indexed, alongside the manifests of every package within it. If there is no such version then the returned
+// map is nil.
+func (a *AFPIndexer) previousIndex() (config.Version, map[string]*config.ProofmanConfig, error) {
+	repo, err := index.Open(a.indexRepositoryUrl)
+	if err != nil {
+		return config.Version{}, nil, errors.Join(err, ErrCannotReadPreviousIndex)
+	}
+
+	versions, err := repo.Versions()
+	if err != nil {
+		return config.Version{}, nil, errors.Join(err, ErrCannotReadPreviousIndex)
+	}
+
+	var previous config.Version
+	found := false
+	for _, v := range versions {
+		if v.Compare(a.afpVersion) < 0 {
+			previous, found = v, true
+		}
+	}
+	if !found {
+		return config.Version{}, nil, nil
+	}
+
+	packages, err := repo.Packages(previous)
+	if err != nil {
+		return config.Version{}, nil, errors.Join(err, ErrCannotReadPreviousIndex)
+	}
+
+	manifests := make(map[string]*config.ProofmanConfig, packages.Size())
+	for pkg := range packages.Items() {
+		manifest, err := repo.Manifest(previous, pkg)
+		if errors.Is(err, index.ErrUnknownPackage) {
+			// the directory does not contain an indexed package
+			continue
+		}
+		if err != nil {
+			return config.Version{}, nil, errors.Join(err, ErrCannotReadPreviousIndex)
+		}
+
+		manifests[pkg] = manifest
+	}
+
+	return previous, manifests, nil
+}
+
+// isabelleVersion returns the Isabelle version that the given AFP version is made against. AFP snapshots are made
+// against the Isabelle release of the same year.
+func isabelleVersion(afpVersion config.Version) string {
+	if afpVersion.IsRelease() {
+		return "Isabelle" + afpVersion.String()
+	}
+
+	return fmt.Sprintf("Isabelle%d", afpVersion.Year)
+}
+
+// reusableManifests returns the manifests of the previous index that may be reused for unchanged packages. The
+// required sessions of a manifest exclude the builtin sessions, so they can only be reused if the previous version
+// was indexed against the same builtin sessions - otherwise nil is returned.
+func reusableManifests(
+	previousVersion config.Version,
+	previous map[string]*config.ProofmanConfig,
+	builtinSessions []string,
+) (map[string]*config.ProofmanConfig, error) {
+	previousBuiltinSessions, err := isabelle.FetchBuiltinSessions(isabelleVersion(previousVersion))
+	if err != nil {
+		return nil, errors.Join(err, ErrCannotResolveBuiltinSessions)
+	}
+
+	if !set.From(previousBuiltinSessions).Equal(set.From(builtinSessions)) {
+		logging.Unquiet("builtin sessions differ from previous index version %s - all packages will be parsed", previousVersion)
+		return nil, nil
+	}
+
+	return previous, nil
+}
+
+// checkCycles finds every group of packages that depend on each other cyclically. The cycles are returned as
+// an error unless Options.AllowCycles is set, in which case they are only reported.
+func (a *AFPIndexer) checkCycles(packageRequires map[string]*set.Set[string]) error {
+	packageGraph := make(graph.Graph, len(packageRequires))
+	for name, requires := range packageRequires {
+		packageGraph[name] = slices.Sorted(requires.Items())
+	}
+
+	cycles := packageGraph.Cycles()
+	if len(cycles) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, 0, len(cycles))
+	for _, cycle := range cycles {
+		edges := make([]string, 0)
+		for _, name := range cycle {
+			for _, required := range packageGraph[name] {
+				if slices.Contains(cycle, required) {
+					edges = append(edges, name+" -> "+required)
+				}
+			}
+		}
+
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s)", strings.Join(cycle, ", "), strings.Join(edges, ", ")))
+	}
+
+	if !a.options.AllowCycles {
+		return errors.Join(ErrCyclicDependencies, errors.New(strings.Join(descriptions, "\n")))
+	}
+
+	logging.Unquiet("warning: found %d group(s) of packages with cyclic dependencies:", len(cycles))
+	for _, description := range descriptions {
+		logging.UnquietIndented(1, "- %s", description)
+	}
+
+	return nil
+}
+
+// summariseChanges reports the packages that were added, removed or changed since the previous index, and the
+// package requirements that were added or removed.
+func summariseChanges(
+	previousVersion config.Version,
+	previous map[string]*config.ProofmanConfig,
+	manifests map[string]*manifest,
+	packageRequires map[string]*set.Set[string],
+) {
+	added, removed, changed, edges := make([]string, 0), make([]string, 0), make([]string, 0), make([]string, 0)
+
+	for _, name := range slices.Sorted(maps.Keys(manifests)) {
+		prev, ok := previous[name]
+		if !ok {
+			added = append(added, name)
+			continue
+		}
+		if prev.Checksum != manifests[name].Checksum {
+			changed = append(changed, name)
+		}
+
+		prevRequires := set.New[string](len(prev.Project.Requires))
+		for _, req := range prev.Project.Requires {
+			prevRequires.Insert(req.Name)
+		}
+		for _, req := range slices.Sorted(packageRequires[name].Difference(prevRequires).Items()) {
+			edges = append(edges, fmt.Sprintf("+ %s -> %s", name, req))
+		}
+		for _, req := range slices.Sorted(prevRequires.Difference(packageRequires[name]).Items()) {
+			edges = append(edges, fmt.Sprintf("- %s -> %s", name, req))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(previous)) {
+		if _, ok := manifests[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	logging.Unquiet(
+		"changes since %s: %d added, %d removed, %d changed, %d unchanged package(s)",
+		previousVersion, len(added), len(removed), len(changed), len(manifests)-len(added)-len(changed),
+	)
+	for _, group := range []struct {
+		label    string
+		packages []string
+	}{{"added", added}, {"removed", removed}, {"changed", changed}} {
+		if len(group.packages) > 0 {
+			logging.UnquietIndented(1, "%s: %s", group.label, strings.Join(group.packages, ", "))
+		}
+	}
+	if len(edges) > 0 {
+		logging.UnquietIndented(1, "requirement changes:")
+		for _, edge := range edges {
+			logging.UnquietIndented(2, "%s", edge)
+		}
+	}
+}
+
+func (a *AFPIndexer) prepareIndexRepo(previousVersion config.Version, incremental bool) error {
+	var err error
+	if incremental {
+		// committing on top of the previous version keeps the commit limited to the packages that changed
+		err = git.SetupRemoteFromBranch(a.indexRepositoryUrl, a.afpDirectoryPath, previousVersion.String())
+	} else {
+		err = git.SetupRemote(a.indexRepositoryUrl, a.afpDirectoryPath)
+	}
 	if err != nil {
 		return errors.Join(err, ErrCannotSetupIndexRepo)
 	}
 
 	// create a new branch for this AFP version
-	err = git.MakeBranch(a.theoriesPath(), a.afpVersion)
+	err = git.MakeBranch(a.theoriesPath(), a.afpVersion.String())
 	if err != nil {
 		return errors.Join(err, ErrCannotMakeBranch)
 	}
@@ -144,13 +640,11 @@ func (a *AFPIndexer) Index() error {
 		return errors.Join(err, ErrCannotReadROOTS)
 	}
 
-	builtinSessions, err := isabelle.FetchBuiltinSessions("Isabelle" + a.afpVersion[:4])
+	builtinSessions, err := isabelle.FetchBuiltinSessions(isabelleVersion(a.afpVersion))
 	if err != nil {
 		return errors.Join(err, ErrCannotResolveBuiltinSessions)
 	}
 
-	manifests := make(map[string]*manifest)
-
 	// parse the ROOT file for all the packages to resolve required and provided sessions
 	rawTheoryPackages := strings.Split(string(contents), "\n")
 	theoryPackages := make([]string, 0)
@@ -163,28 +657,43 @@ func (a *AFPIndexer) Index() error {
 		theoryPackages = append(theoryPackages, trimmed)
 	}
 
+	// compare against the previous version within the index repository so that unchanged packages do not
+	// need to be parsed again - the repository is only read from, so this is done for dry runs too
+	var previousVersion config.Version
+	var previous, reusable map[string]*config.ProofmanConfig
+	if !a.options.Full && a.indexRepositoryUrl != "" {
+		previousVersion, previous, err = a.previousIndex()
+		if err != nil {
+			return err
+		}
+		if previous != nil {
+			logging.Unquiet("comparing against previous index version %s", previousVersion)
+
+			reusable, err = reusableManifests(previousVersion, previous, builtinSessions)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	var pbar *progressbar.ProgressBar
 	if internal.LogLevel == internal.LogLvlQuiet {
-		pbar = progressbar.DefaultSilent(int64(len(rawTheoryPackages)))
+		pbar = progressbar.DefaultSilent(int64(len(theoryPackages)))
 	} else {
 		pbar = progressbar.Default(int64(len(theoryPackages)), "parsing")
 	}
 
-	for _, pkg := range theoryPackages {
-		if strings.TrimSpace(pkg) == "" {
-			continue
-		}
-
-		m, err := a.resolveManifest(strings.TrimSpace(pkg), builtinSessions)
-		if err != nil {
-			return err
-		}
-
-		manifests[m.Name] = m
-
-		_ = pbar.Add(1)
+	manifests, err := a.resolveManifests(theoryPackages, builtinSessions, reusable, pbar)
+	if err != nil {
+		return err
 	}
 
+	skippedDependents := skipDependentSessions(manifests, builtinSessions)
+	theoryPackages = slices.DeleteFunc(theoryPackages, func(name string) bool {
+		_, ok := manifests[name]
+		return !ok
+	})
+
 	// create a map to allow us to resolve the package that provides a given session
 	sessionsToPackage := make(map[string]string)
 	for k, v := range manifests {
@@ -198,13 +707,22 @@ func (a *AFPIndexer) Index() error {
 
 	// resolve which packages are required by each package
 	packageRequires := make(map[string]*set.Set[string])
-	for name, m := range manifests {
+	unknownSessions := make([]error, 0)
+	for _, name := range theoryPackages {
+		m := manifests[name]
 		packageRequires[name] = set.New[string](0)
 
-		for s := range m.RequiresSessions.Items() {
+		for _, s := range slices.Sorted(m.RequiresSessions.Items()) {
 			elem, ok := sessionsToPackage[s]
 			if !ok {
-				return errors.Join(ErrUnknownSession, errors.New(s))
+				// qualifiers of theory imports are not checked by the ROOT parser, so a session that is only
+				// imported may not exist - this is reported as a disagreement rather than failing the index
+				if m.ImportedSessions.Contains(s) {
+					logging.Verbose("theory package %s imports from unknown session %s - skipping", name, s)
+					continue
+				}
+				unknownSessions = append(unknownSessions, fmt.Errorf("%s: %w - %s", name, ErrUnknownSession, s))
+				continue
 			}
 
 			packageRequires[name].Insert(elem)
@@ -213,47 +731,151 @@ func (a *AFPIndexer) Index() error {
 		_ = pbar.Add(1)
 	}
 
-	pbar.Reset()
-	pbar.Describe("writing")
+	if err = errors.Join(unknownSessions...); err != nil {
+		return err
+	}
 
-	// copy the files from the AFP directory into the index repo for each of the packages
-	for pkgName := range manifests {
-		logging.Verbose("creating proofman config file for %s", pkgName)
+	if err = a.checkCycles(packageRequires); err != nil {
+		return err
+	}
 
-		// create a proofman.toml file for this package
-		requiresPkgs := make([]string, 0)
+	metadata, err := loadMetadata(a.afpDirectoryPath)
+	if err != nil {
+		return err
+	}
+
+	// create a proofman.toml file for each of the packages
+	configs := make(map[string][]byte, len(manifests))
+	packageConfigs := make([]*config.ProofmanConfig, 0, len(manifests))
+	for _, pkgName := range theoryPackages {
+		requiresPkgs := make([]config.Requirement, 0)
 		if reqs, ok := packageRequires[pkgName]; ok {
-			for req := range reqs.Items() {
-				requiresPkgs = append(requiresPkgs, req+" @ "+a.afpVersion)
+			for _, req := range slices.Sorted(reqs.Items()) {
+				requiresPkgs = append(requiresPkgs, config.Requirement{Name: req, Constraint: config.ExactConstraint(a.afpVersion)})
 			}
 		}
 
-		marshalled, err := toml.Marshal(config.ProofmanConfig{
+		pkgConfig := &config.ProofmanConfig{
+			Checksum: manifests[pkgName].Checksum,
 			Project: config.Project{
 				Name:        pkgName,
 				Description: pkgName + " from the Archive of Formal Proofs",
-				Version:     a.afpVersion,
+				Version:     a.afpVersion.String(),
 				Requires:    requiresPkgs,
 			},
-		})
+			Sessions: manifests[pkgName].Sessions,
+		}
+		if err = metadata.apply(&pkgConfig.Project); err != nil {
+			return fmt.Errorf("%s: %w", pkgName, err)
+		}
+
+		marshalled, err := toml.Marshal(pkgConfig)
 		if err != nil {
 			return errors.Join(err, ErrCannotPopulateRepo)
 		}
 
-		err = os.WriteFile(
-			filepath.Join(a.theoriesPath(), pkgName, internal.ConfigFileName),
-			marshalled,
-			0666,
+		configs[pkgName] = marshalled
+		packageConfigs = append(packageConfigs, pkgConfig)
+	}
+
+	// create the aggregate index file, so consumers can read every package in one go
+	indexFile, err := index.NewFile(a.afpVersion, packageConfigs).Marshal()
+	if err != nil {
+		return errors.Join(err, ErrCannotPopulateRepo)
+	}
+
+	// report the packages whose ROOT file does not agree with the imports of their theories
+	disagreeing := make([]string, 0)
+	for name, m := range manifests {
+		if len(m.Disagreements) > 0 {
+			disagreeing = append(disagreeing, name)
+		}
+	}
+	if len(disagreeing) > 0 {
+		slices.Sort(disagreeing)
+
+		logging.Unquiet("ROOT sessions and theory imports disagree for %d package(s):", len(disagreeing))
+		for _, name := range disagreeing {
+			for _, disagreement := range manifests[name].Disagreements {
+				logging.UnquietIndented(1, "- %s: %s", name, disagreement)
+			}
+		}
+	}
+
+	// report the packages with invalid sessions that were skipped
+	invalid := make([]string, 0)
+	for name, m := range manifests {
+		if len(m.Diagnostics) > 0 {
+			invalid = append(invalid, name)
+		}
+	}
+	if len(invalid) > 0 {
+		slices.Sort(invalid)
+
+		logging.Unquiet("warning: skipped invalid ROOT file constructs in %d package(s):", len(invalid))
+		for _, name := range invalid {
+			for _, diagnostic := range manifests[name].Diagnostics {
+				logging.Unquiet("%s", diagnostic)
+			}
+		}
+	}
+	if len(skippedDependents) > 0 {
+		logging.Unquiet("warning: skipped sessions depending on invalid ROOT file constructs:")
+		for _, description := range skippedDependents {
+			logging.UnquietIndented(1, "- %s", description)
+		}
+	}
+
+	if previous != nil {
+		summariseChanges(previousVersion, previous, manifests, packageRequires)
+	}
+
+	switch {
+	case a.options.DryRun:
+		for _, pkgName := range theoryPackages {
+			logging.Quiet("# %s", filepath.Join(theoriesDirName, pkgName, internal.ConfigFileName))
+			logging.Quiet("%s", configs[pkgName])
+		}
+		logging.Quiet("# %s", index.FileName)
+		logging.Quiet("%s", indexFile)
+		logging.Quiet("dry run complete - %d manifest(s) would be written", len(configs))
+		return nil
+	case a.options.OutputDirectory != "":
+		written, err := a.writeOutputDirectory(theoryPackages, configs, pbar)
+		if err != nil {
+			return err
+		}
+		if _, err = writeIfChanged(filepath.Join(a.options.OutputDirectory, index.FileName), indexFile); err != nil {
+			return err
+		}
+		logging.Quiet(
+			"indexing complete - index written to %s (%d manifest(s) written, %d unchanged)",
+			a.options.OutputDirectory, written, len(theoryPackages)-written,
 		)
+		return nil
+	}
+
+	pbar.Reset()
+	pbar.Describe("writing")
+
+	// write the manifests into the AFP directory, which becomes the new index repository branch
+	for _, pkgName := range theoryPackages {
+		written, err := writeIfChanged(filepath.Join(a.theoriesPath(), pkgName, internal.ConfigFileName), configs[pkgName])
 		if err != nil {
-			return errors.Join(err, ErrCannotPopulateRepo)
+			return err
+		}
+		if written {
+			logging.Verbose("created proofman config file for %s", pkgName)
 		}
 
 		_ = pbar.Add(1)
 	}
+	if _, err = writeIfChanged(filepath.Join(a.afpDirectoryPath, index.FileName), indexFile); err != nil {
+		return err
+	}
 
 	// push the changes to the upstream
-	err = a.prepareIndexRepo()
+	err = a.prepareIndexRepo(previousVersion, previous != nil)
 	if err != nil {
 		return err
 	}
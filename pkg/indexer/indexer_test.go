@@ -0,0 +1,347 @@
+package indexer
+
+import (
+	"fmt"
+	"github.com/schollz/progressbar/v3"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/index"
+	"github.com/tandemdude/proofman/pkg/localcache"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolveManifestImports(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"thys/A/ROOT":    "chapter AFP\n\nsession A = HOL +\n  sessions B C\n  theories Foo\n",
+		"thys/A/Foo.thy": "theory Foo imports \"B.Bar\" \"D.Baz\" \"HOL-Library.Multiset\" Main begin end",
+	})
+
+	a := &AFPIndexer{afpDirectoryPath: dir}
+	m, err := a.resolveManifest("A", []string{"HOL", "HOL-Library", "Pure"})
+	assert.NoError(err)
+
+	assert.Equal([]string{"B", "C", "D"}, slices.Sorted(m.RequiresSessions.Items()))
+	assert.Equal([]string{"D"}, slices.Sorted(m.ImportedSessions.Items()))
+	assert.Equal([]string{"B", "D"}, m.Sessions[0].Imports)
+	assert.Len(m.Disagreements, 2)
+}
+
+func TestResolvePackageReusesUnchangedManifests(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"thys/A/ROOT":    "session A = HOL +\n  sessions B\n  theories Foo\n",
+		"thys/A/Foo.thy": "theory Foo imports \"B.Bar\" begin end",
+	})
+
+	a := &AFPIndexer{afpDirectoryPath: dir}
+	builtinSessions := []string{"HOL", "Pure"}
+
+	m, err := a.resolvePackage("A", builtinSessions, nil)
+	assert.NoError(err)
+	assert.NotEmpty(m.Checksum)
+
+	// the previous manifest is used as written when the package is unchanged
+	previous := &config.ProofmanConfig{
+		Checksum: m.Checksum,
+		Sessions: []config.Session{{Name: "A", Parent: "HOL", Requires: []string{"C"}, Imports: []string{"C"}}},
+	}
+	reused, err := a.resolvePackage("A", builtinSessions, previous)
+	assert.NoError(err)
+	assert.Equal([]string{"C"}, slices.Sorted(reused.RequiresSessions.Items()))
+
+	// and ignored once the package changes
+	writeFiles(t, dir, map[string]string{"thys/A/Foo.thy": "theory Foo imports \"B.Bar\" Main begin end"})
+	changed, err := a.resolvePackage("A", builtinSessions, previous)
+	assert.NoError(err)
+	assert.NotEqual(m.Checksum, changed.Checksum)
+	assert.Equal([]string{"B"}, slices.Sorted(changed.RequiresSessions.Items()))
+}
+
+func TestResolveManifestParentSession(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"thys/A/ROOT":    "chapter AFP\n\nsession A = B +\n  theories Foo\n\nsession A_Examples = A +\n  theories Ex\n",
+		"thys/A/Foo.thy": "theory Foo imports \"B.Bar\" Main begin end",
+		"thys/A/Ex.thy":  "theory Ex imports \"A.Foo\" begin end",
+	})
+
+	a := &AFPIndexer{afpDirectoryPath: dir}
+	m, err := a.resolveManifest("A", []string{"HOL", "Pure"})
+	assert.NoError(err)
+
+	assert.Equal([]string{"B"}, slices.Sorted(m.RequiresSessions.Items()))
+	assert.Equal(0, m.ImportedSessions.Size())
+	assert.Empty(m.Disagreements)
+
+	// builtin parents are not required
+	writeFiles(t, dir, map[string]string{"thys/A/ROOT": "session A = HOL +\n  theories Foo\n"})
+	m, err = a.resolveManifest("A", []string{"HOL", "Pure"})
+	assert.NoError(err)
+	assert.Equal([]string{"B"}, slices.Sorted(m.RequiresSessions.Items()))
+	assert.Equal([]string{"B"}, slices.Sorted(m.ImportedSessions.Items()))
+}
+
+func TestReusableManifests(t *testing.T) {
+	assert := asrt.New(t)
+
+	t.Setenv("HOME", t.TempDir())
+	assert.NoError(localcache.WriteFile("Isabelle2023.builtin_sessions", "HOL\nPure"))
+	assert.NoError(localcache.WriteFile("Isabelle2024.builtin_sessions", "HOL\nHOL-Library\nPure"))
+
+	previous := map[string]*config.ProofmanConfig{"A": {Checksum: "a"}}
+	previousVersion, err := config.ParseVersion("2023-06-01")
+	assert.NoError(err)
+
+	reusable, err := reusableManifests(previousVersion, previous, []string{"Pure", "HOL"})
+	assert.NoError(err)
+	assert.Equal(previous, reusable)
+
+	// a session that became builtin must be removed from the required sessions, so nothing can be reused
+	reusable, err = reusableManifests(previousVersion, previous, []string{"HOL", "HOL-Library", "Pure"})
+	assert.NoError(err)
+	assert.Nil(reusable)
+}
+
+func TestSkipDependentSessions(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"thys/A/ROOT": "session A1 = HOL +\n  theories Foo\n" +
+			"session A2 = HOL +\n  theories \"Bar\n" +
+			"session A3 = A2 +\n  theories Baz\n" +
+			"session A4 = HOL +\n  theories Qux\n",
+		"thys/B/ROOT": "session B = HOL +\n  sessions A2\n  theories Foo\n",
+		"thys/C/ROOT": "session C = B +\n  theories Foo\n",
+		"thys/D/ROOT": "session D = A1 +\n  theories Foo\n",
+	})
+
+	builtinSessions := []string{"HOL", "Pure"}
+	a := &AFPIndexer{afpDirectoryPath: dir}
+	manifests := make(map[string]*manifest)
+	for _, name := range []string{"A", "B", "C", "D"} {
+		m, err := a.resolveManifest(name, builtinSessions)
+		assert.NoError(err)
+		manifests[name] = m
+	}
+
+	// the lexer error only skips the session containing it
+	assert.Len(manifests["A"].Diagnostics, 1)
+	assert.Equal([]string{"A1", "A3", "A4"}, slices.Sorted(manifests["A"].ProvidesSessions.Items()))
+
+	descriptions := skipDependentSessions(manifests, builtinSessions)
+	assert.Equal([]string{
+		"A: session A3 requires A2, which was skipped",
+		"B: session B requires A2, which was skipped",
+		"B: no sessions remain - skipping package",
+		"C: session C requires B, which was skipped",
+		"C: no sessions remain - skipping package",
+	}, descriptions)
+
+	assert.Equal([]string{"A", "D"}, slices.Sorted(maps.Keys(manifests)))
+	assert.Equal([]string{"A1", "A4"}, slices.Sorted(manifests["A"].ProvidesSessions.Items()))
+	assert.Zero(manifests["A"].RequiresSessions.Size())
+	assert.Len(manifests["A"].Diagnostics, 1)
+	assert.Equal([]string{"A1"}, slices.Sorted(manifests["D"].RequiresSessions.Items()))
+}
+
+func TestWriteOutputDirectory(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir, output := t.TempDir(), t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"thys/A/ROOT": "session A = HOL +\n  theories Foo\n",
+		"thys/B/ROOT": "session B = HOL +\n  theories Foo\n",
+	})
+
+	a := &AFPIndexer{afpDirectoryPath: dir, options: Options{OutputDirectory: output}}
+	pbar := progressbar.DefaultSilent(0)
+
+	written, err := a.writeOutputDirectory([]string{"A", "B"}, map[string][]byte{"A": []byte("a"), "B": []byte("b")}, pbar)
+	assert.NoError(err)
+	assert.Equal(2, written)
+
+	// only the package whose manifest changed is written again
+	writeFiles(t, output, map[string]string{"thys/A/marker": "", "thys/B/marker": ""})
+	written, err = a.writeOutputDirectory([]string{"A", "B"}, map[string][]byte{"A": []byte("a"), "B": []byte("c")}, pbar)
+	assert.NoError(err)
+	assert.Equal(1, written)
+
+	assert.FileExists(filepath.Join(output, "thys", "A", "marker"))
+	assert.NoFileExists(filepath.Join(output, "thys", "B", "marker"))
+	content, err := os.ReadFile(filepath.Join(output, "thys", "B", "proofman.toml"))
+	assert.NoError(err)
+	assert.Equal("c", string(content))
+
+	path := filepath.Join(output, "index.toml")
+	for _, expected := range []bool{true, false} {
+		written, err := writeIfChanged(path, []byte("index"))
+		assert.NoError(err)
+		assert.Equal(expected, written)
+	}
+}
+
+func TestResolveManifests(t *testing.T) {
+	dir := t.TempDir()
+	packages := make([]string, 0)
+	for i := range 8 {
+		name := fmt.Sprintf("P%d", i)
+		writeFiles(t, dir, map[string]string{
+			"thys/" + name + "/ROOT":    "session " + name + " = HOL +\n  theories Foo\n",
+			"thys/" + name + "/Foo.thy": "theory Foo imports Main begin end",
+		})
+		packages = append(packages, name)
+	}
+	writeFiles(t, dir, map[string]string{"thys/Invalid/ROOT": "session = HOL +\n"})
+
+	for _, jobs := range []int{0, 1, 4, 16} {
+		t.Run(fmt.Sprintf("%d jobs", jobs), func(t *testing.T) {
+			assert := asrt.New(t)
+
+			a := &AFPIndexer{afpDirectoryPath: dir, options: Options{Jobs: jobs}}
+			manifests, err := a.resolveManifests(packages, []string{"HOL", "Pure"}, nil, progressbar.DefaultSilent(0))
+			assert.NoError(err)
+			assert.Equal(packages, slices.Sorted(maps.Keys(manifests)))
+			for _, name := range packages {
+				assert.Equal([]string{name}, slices.Collect(manifests[name].ProvidesSessions.Items()))
+			}
+
+			// the errors of every package that could not be resolved are returned together
+			invalid := append([]string{"Invalid", "Missing"}, packages...)
+			_, err = a.resolveManifests(invalid, []string{"HOL", "Pure"}, nil, progressbar.DefaultSilent(0))
+			assert.ErrorIs(err, ErrCannotParseROOT)
+			assert.ErrorIs(err, ErrCannotHashPackage)
+			assert.ErrorContains(err, "Invalid: ")
+			assert.ErrorContains(err, "Missing: ")
+		})
+	}
+}
+
+// testAFP creates an AFP directory containing two packages, where B depends upon A, and caches the builtin sessions
+// of the Isabelle version it is indexed against.
+func testAFP(t *testing.T) string {
+	t.Setenv("HOME", t.TempDir())
+	if err := localcache.WriteFile("Isabelle2024.builtin_sessions", "HOL\nPure"); err != nil {
+		t.Fatal(err)
+	}
+
+	level := internal.LogLevel
+	t.Cleanup(func() { internal.LogLevel = level })
+	internal.LogLevel = internal.LogLvlQuiet
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"thys/ROOTS":     "A\nB\n",
+		"thys/A/ROOT":    "chapter AFP\n\nsession A = HOL +\n  theories Foo\n",
+		"thys/A/Foo.thy": "theory Foo imports Main begin end",
+		"thys/B/ROOT":    "chapter AFP\n\nsession B = A +\n  theories Bar\n",
+		"thys/B/Bar.thy": "theory Bar imports A.Foo begin end",
+	})
+
+	return dir
+}
+
+// captureStdout returns everything written to stdout by the given function, alongside the error it returned.
+func captureStdout(t *testing.T, f func() error) (string, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	err = f()
+	_ = writer.Close()
+
+	output, readErr := io.ReadAll(reader)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	return string(output), err
+}
+
+func TestIndexDryRun(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir := testAFP(t)
+	a, err := New(dir, "", "2024-01-01", Options{DryRun: true})
+	assert.NoError(err)
+
+	output, err := captureStdout(t, a.Index)
+	assert.NoError(err)
+	assert.Contains(output, "# thys/A/proofman.toml\n")
+	assert.Contains(output, "# thys/B/proofman.toml\n")
+	assert.Contains(output, "dry run complete - 2 manifest(s) would be written\n")
+
+	// nothing is written into the AFP directory
+	assert.NoFileExists(filepath.Join(dir, "thys", "A", "proofman.toml"))
+	assert.NoFileExists(filepath.Join(dir, "thys", "B", "proofman.toml"))
+	assert.NoDirExists(filepath.Join(dir, ".git"))
+}
+
+func TestIndexOutputDirectory(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir, output := testAFP(t), t.TempDir()
+	a, err := New(dir, "", "2024-01-01", Options{OutputDirectory: output})
+	assert.NoError(err)
+	_, err = captureStdout(t, a.Index)
+	assert.NoError(err)
+
+	// the packages are copied into the output directory alongside their manifests, leaving the AFP untouched
+	assert.FileExists(filepath.Join(output, "thys", "B", "Bar.thy"))
+	assert.NoFileExists(filepath.Join(dir, "thys", "B", "proofman.toml"))
+	assert.NoDirExists(filepath.Join(dir, ".git"))
+
+	cfg, err := config.FromFile(filepath.Join(output, "thys", "B"))
+	assert.NoError(err)
+	assert.Equal("2024-01-01", cfg.Project.Version)
+	if assert.Len(cfg.Project.Requires, 1) {
+		assert.Equal("A @ 2024-01-01", cfg.Project.Requires[0].String())
+	}
+	assert.Equal([]config.Session{{Name: "B", Parent: "A", Imports: []string{"A"}}}, cfg.Sessions)
+
+	// the aggregate index file summarises every package that was indexed
+	content, err := os.ReadFile(filepath.Join(output, "index.toml"))
+	assert.NoError(err)
+	file, err := index.ParseFile(content)
+	assert.NoError(err)
+	assert.Equal("2024-01-01", file.Version.String())
+	assert.Len(file.Packages, 2)
+	if entry := file.Entry("B"); assert.NotNil(entry) {
+		assert.Equal([]string{"B"}, entry.Sessions)
+		if assert.Len(entry.Requires, 1) {
+			assert.Equal("A @ 2024-01-01", entry.Requires[0].String())
+		}
+		assert.NotEmpty(entry.Checksum)
+	}
+	assert.NotNil(file.Entry("A"))
+}
@@ -0,0 +1,204 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/config"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	metadataDirName  = "metadata"
+	entriesDirName   = "entries"
+	authorsFileName  = "authors.toml"
+	licensesFileName = "licenses.toml"
+
+	entryHomepageUrl = "https://www.isa-afp.org/entries/%s.html"
+)
+
+var ErrCannotReadMetadata = errors.New("failed reading AFP metadata")
+
+type entryMetadata struct {
+	Title    string         `toml:"title"`
+	Topics   []string       `toml:"topics"`
+	Abstract string         `toml:"abstract"`
+	License  string         `toml:"license"`
+	Authors  map[string]any `toml:"authors"`
+}
+
+type namedMetadata struct {
+	Name string `toml:"name"`
+}
+
+// afpMetadata is the entry metadata shipped within the 'metadata' directory of an AFP checkout. Each entry has
+// a file 'metadata/entries/<entry>.toml', which refers to authors and licenses by their identifiers within the
+// 'metadata/authors.toml' and 'metadata/licenses.toml' files.
+type afpMetadata struct {
+	directory string
+	authors   map[string]namedMetadata
+	licenses  map[string]namedMetadata
+}
+
+func readTomlFile(path string, v any) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return toml.Unmarshal(content, v)
+}
+
+// loadMetadata reads the author and license metadata of the AFP. If the AFP checkout does not contain any
+// metadata then nil is returned, and packages are indexed without it.
+func loadMetadata(afpDirectoryPath string) (*afpMetadata, error) {
+	directory := filepath.Join(afpDirectoryPath, metadataDirName)
+	if exists, _ := internal.PathExists(filepath.Join(directory, entriesDirName)); !exists {
+		logging.Unquiet("AFP directory does not contain entry metadata - packages will be indexed without it")
+		return nil, nil
+	}
+
+	metadata := &afpMetadata{
+		directory: directory,
+		authors:   make(map[string]namedMetadata),
+		licenses:  make(map[string]namedMetadata),
+	}
+	if err := readTomlFile(filepath.Join(directory, authorsFileName), &metadata.authors); err != nil {
+		return nil, errors.Join(err, ErrCannotReadMetadata)
+	}
+	if err := readTomlFile(filepath.Join(directory, licensesFileName), &metadata.licenses); err != nil {
+		return nil, errors.Join(err, ErrCannotReadMetadata)
+	}
+
+	return metadata, nil
+}
+
+// truncateDescription shortens the given text to fit within the maximum length of a project description, cutting
+// at the last word boundary that fits.
+func truncateDescription(text string) string {
+	const ellipsis = "..."
+
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= config.MaxDescriptionLength {
+		return text
+	}
+
+	cut := config.MaxDescriptionLength - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if space := strings.LastIndex(text[:cut], " "); space > 0 {
+		cut = space
+	}
+
+	return strings.TrimRight(text[:cut], " ,.;:") + ellipsis
+}
+
+// authorOrder returns the identifiers of the authors of an entry metadata file, in the order they are declared in.
+// The order is lost when decoding the authors into a map, so the document is walked instead.
+func authorOrder(content []byte) ([]string, error) {
+	ids := make([]string, 0)
+	add := func(id string) {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	parser := unstable.Parser{}
+	parser.Reset(content)
+
+	// authors are either declared as '[authors.<id>]' tables, or as keys within the '[authors]' table
+	var table []string
+	for parser.NextExpression() {
+		expression := parser.Expression()
+
+		keys := make([]string, 0)
+		for it := expression.Key(); it.Next(); {
+			keys = append(keys, string(it.Node().Data))
+		}
+
+		switch expression.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			table = keys
+			if len(keys) >= 2 && keys[0] == "authors" {
+				add(keys[1])
+			}
+		case unstable.KeyValue:
+			if len(table) == 0 && len(keys) >= 2 && keys[0] == "authors" {
+				add(keys[1])
+			} else if slices.Equal(table, []string{"authors"}) && len(keys) >= 1 {
+				add(keys[0])
+			}
+		default:
+		}
+	}
+
+	return ids, parser.Error()
+}
+
+// apply fills the project with the metadata of the AFP entry of the same name. Entries without metadata are
+// left unchanged.
+func (m *afpMetadata) apply(project *config.Project) error {
+	if m == nil {
+		return nil
+	}
+
+	path := filepath.Join(m.directory, entriesDirName, project.Name+".toml")
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logging.Verbose("no metadata found for %s", project.Name)
+		return nil
+	}
+	if err != nil {
+		return errors.Join(err, ErrCannotReadMetadata)
+	}
+
+	entry := entryMetadata{}
+	if err = toml.Unmarshal(content, &entry); err != nil {
+		return errors.Join(err, ErrCannotReadMetadata)
+	}
+
+	// titles may be longer than is permitted for a description, so the full title is kept separately
+	if entry.Title != "" {
+		project.Title = entry.Title
+		project.Description = truncateDescription(entry.Title)
+	}
+	project.Abstract = strings.TrimSpace(entry.Abstract)
+	project.Topics = entry.Topics
+	project.Homepage = fmt.Sprintf(entryHomepageUrl, project.Name)
+
+	project.License = entry.License
+	if license, ok := m.licenses[entry.License]; ok && license.Name != "" {
+		project.License = license.Name
+	}
+
+	authorIds, err := authorOrder(content)
+	if err != nil {
+		return errors.Join(err, ErrCannotReadMetadata)
+	}
+	// fall back to a stable order for any authors the walk did not find, such as those within an inline table
+	for _, id := range slices.Sorted(maps.Keys(entry.Authors)) {
+		if !slices.Contains(authorIds, id) {
+			authorIds = append(authorIds, id)
+		}
+	}
+
+	project.Authors = make([]string, 0, len(authorIds))
+	for _, id := range authorIds {
+		if author, ok := m.authors[id]; ok && author.Name != "" {
+			project.Authors = append(project.Authors, author.Name)
+		} else {
+			project.Authors = append(project.Authors, id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package indexer
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/pkg/config"
+	"strings"
+	"testing"
+)
+
+func TestAuthorOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "author tables",
+			content:  "title = \"T\"\n[authors]\n\n[authors.zed]\nemail = \"zed_email\"\n\n[ authors.alice ]\n",
+			expected: []string{"zed", "alice"},
+		},
+		{
+			name:     "keys within the authors table",
+			content:  "[authors]\nzed = { email = \"zed_email\" }\nalice = {}\n\n[contributors.bob]\n",
+			expected: []string{"zed", "alice"},
+		},
+		{
+			name:     "dotted keys",
+			content:  "authors.zed.email = \"zed_email\"\nauthors.alice = {}\n",
+			expected: []string{"zed", "alice"},
+		},
+		{
+			name:     "quoted keys and comments",
+			content:  "# [authors.nobody]\n[authors.\"zed\"]\n[authors.alice] # trailing\n",
+			expected: []string{"zed", "alice"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := asrt.New(t)
+
+			ids, err := authorOrder([]byte(test.content))
+			assert.NoError(err)
+			assert.Equal(test.expected, ids)
+		})
+	}
+}
+
+func TestApplyMetadata(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir := t.TempDir()
+	longTitle := "A Formalisation of " + strings.Repeat("Extremely ", 12) + "Long Titles"
+	writeFiles(t, dir, map[string]string{
+		"metadata/entries/A.toml": "title = \"The A Entry\"\ntopics = [\"Logic\"]\nabstract = \"\"\"\n  Abstract.\n\"\"\"\n" +
+			"license = \"bsd\"\n\n[authors]\n\n[authors.zed]\nemail = \"zed_email\"\n\n[authors.alice]\n\n" +
+			"[authors.unknown]\n",
+		"metadata/entries/Long.toml": "title = \"" + longTitle + "\"\n",
+		"metadata/authors.toml":      "[alice]\nname = \"Alice Smith\"\n\n[zed]\nname = \"Zed Ålund\"\n",
+		"metadata/licenses.toml":     "[bsd]\nname = \"BSD\"\n",
+	})
+
+	metadata, err := loadMetadata(dir)
+	assert.NoError(err)
+
+	project := &config.Project{Name: "A", Description: "unchanged", Version: "2024-01-01"}
+	assert.NoError(metadata.apply(project))
+	assert.Equal(&config.Project{
+		Name:        "A",
+		Description: "The A Entry",
+		Version:     "2024-01-01",
+		Title:       "The A Entry",
+		Authors:     []string{"Zed Ålund", "Alice Smith", "unknown"},
+		License:     "BSD",
+		Topics:      []string{"Logic"},
+		Homepage:    "https://www.isa-afp.org/entries/A.html",
+		Abstract:    "Abstract.",
+	}, project)
+
+	// long titles are kept in full, with the description shortened so that the manifest remains valid
+	project = &config.Project{Name: "Long", Version: "2024-01-01"}
+	assert.NoError(metadata.apply(project))
+	assert.Equal(longTitle, project.Title)
+	assert.LessOrEqual(len(project.Description), config.MaxDescriptionLength)
+	assert.True(strings.HasPrefix(project.Description, "A Formalisation of Extremely"))
+	assert.True(strings.HasSuffix(project.Description, "Extremely..."))
+	assert.NoError(config.Validate(&config.ProofmanConfig{Project: *project}))
+
+	// entries without metadata are left unchanged
+	project = &config.Project{Name: "B", Description: "unchanged"}
+	assert.NoError(metadata.apply(project))
+	assert.Equal(&config.Project{Name: "B", Description: "unchanged"}, project)
+
+	// as are AFP checkouts without any metadata
+	metadata, err = loadMetadata(t.TempDir())
+	assert.NoError(err)
+	assert.NoError(metadata.apply(project))
+	assert.Equal(&config.Project{Name: "B", Description: "unchanged"}, project)
+}
+
+func TestTruncateDescription(t *testing.T) {
+	assert := asrt.New(t)
+
+	assert.Equal("Short title", truncateDescription("Short title"))
+	// whitespace is collapsed, as titles may span multiple lines
+	assert.Equal("Multi line title", truncateDescription("Multi\n  line title"))
+
+	// multibyte characters are never split
+	truncated := truncateDescription(strings.Repeat("ä", 60))
+	assert.LessOrEqual(len(truncated), config.MaxDescriptionLength)
+	assert.Equal(strings.Repeat("ä", 48)+"...", truncated)
+}
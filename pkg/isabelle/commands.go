@@ -2,7 +2,9 @@ package isabelle
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -43,3 +45,20 @@ func MkRoot() (string, error) {
 func Install(dir string) (string, error) {
 	return runIsabelleCommand("install", dir)
 }
+
+// Build builds the given sessions using the isabelle executable of the virtual environment at venvRoot, so the
+// installed packages are available without the virtual environment being active. Sessions defined within each of
+// the given directories are also made available. The build output is written to stdout and stderr as it happens.
+func Build(venvRoot string, directories []string, sessions []string) error {
+	args := []string{"build"}
+	for _, dir := range directories {
+		args = append(args, "-d", dir)
+	}
+	args = append(args, sessions...)
+
+	cmd := exec.Command(filepath.Join(venvRoot, "bin", "isabelle"), args...)
+	cmd.Env = append(os.Environ(), "PROOFMAN_VENV_ROOT="+venvRoot)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+
+	return cmd.Run()
+}
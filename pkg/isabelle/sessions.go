@@ -80,7 +80,7 @@ func FetchBuiltinSessions(versionOverride string) ([]string, error) {
 		}
 		res.Body.Close()
 
-		parsed, err := parser.ParseRootFile(bytes.NewReader(content))
+		parsed, err := parser.ParseNamedRootFile(rootLocation, bytes.NewReader(content))
 		if err != nil {
 			logging.Unquiet("parsing %s ROOT file failed - %s", rootLocation, err)
 			internal.WriteFile(".failed.ROOT", string(content), false)
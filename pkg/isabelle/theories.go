@@ -0,0 +1,97 @@
+package isabelle
+
+import (
+	"bytes"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/internal/logging"
+	"github.com/tandemdude/proofman/pkg/parser"
+	"github.com/tandemdude/proofman/pkg/parser/structure"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// TheoryImport is a qualified import, such as "HOL-Library.Multiset", found within the header of a theory file.
+type TheoryImport struct {
+	// Theory is the session theory entry whose file contains the import
+	Theory string
+	Import string
+	// Session is the qualifier of the import, i.e. the session the imported theory belongs to
+	Session string
+}
+
+// ImportSession returns the session that a qualified theory import refers to. Imports which are not qualified,
+// or which refer to a theory by its file path, do not name a session.
+func ImportSession(theoryImport string) (string, bool) {
+	if strings.ContainsAny(theoryImport, `/$~`) {
+		return "", false
+	}
+
+	session, _, found := strings.Cut(theoryImport, ".")
+	if !found || session == "" {
+		return "", false
+	}
+
+	return session, true
+}
+
+// TheoryFiles resolves the files of all the theories listed by a session. The theories are searched for within
+// the session directory, and each additional directory listed by the session. Theory entries that cannot be found,
+// for example because they are qualified with the name of another session, are omitted.
+func TheoryFiles(rootDirectory string, session *structure.Session) map[string]string {
+	sessionDirectory := filepath.Join(rootDirectory, session.Dir)
+
+	searchDirectories := []string{sessionDirectory}
+	for _, dir := range session.Directories {
+		searchDirectories = append(searchDirectories, filepath.Join(sessionDirectory, dir))
+	}
+
+	files := make(map[string]string)
+	for _, theories := range session.Theories {
+		for _, entry := range theories.Entries {
+			for _, dir := range searchDirectories {
+				candidate := filepath.Join(dir, entry+".thy")
+				if exists, _ := internal.PathExists(candidate); exists {
+					files[entry] = candidate
+					break
+				}
+			}
+		}
+	}
+
+	return files
+}
+
+// SessionImports parses the header of every theory file of a session, and returns all the qualified imports they
+// contain. Theory files that cannot be parsed are skipped.
+func SessionImports(rootDirectory string, session *structure.Session) ([]TheoryImport, error) {
+	imports := make([]TheoryImport, 0)
+
+	files := TheoryFiles(rootDirectory, session)
+	for _, entry := range slices.Sorted(maps.Keys(files)) {
+		file := files[entry]
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		theories, err := parser.ParseNamedTheoryFile(file, bytes.NewReader(content))
+		if err != nil {
+			logging.Verbose("skipping theory %s of session %s - %s", entry, session.Name, err)
+			continue
+		}
+
+		for _, theory := range theories {
+			for _, imp := range theory.Imports {
+				if qualifier, ok := ImportSession(imp); ok {
+					imports = append(imports, TheoryImport{Theory: entry, Import: imp, Session: qualifier})
+				}
+			}
+		}
+	}
+
+	return imports, nil
+}
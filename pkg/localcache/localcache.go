@@ -52,3 +52,12 @@ func ReadFile(name string) string {
 
 	return string(content)
 }
+
+func Path(name string) (string, error) {
+	base, err := basePath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(base, name), nil
+}
@@ -0,0 +1,110 @@
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-set/v3"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/pkg/config"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const FileName = "proofman.lock"
+
+var ErrOutOfDate = errors.New(FileName + " is out of date with " + internal.ConfigFileName + " - run 'proofman lock' to update it")
+
+type Package struct {
+	Name     string               `toml:"name"`
+	Version  config.Version       `toml:"version"`
+	Commit   string               `toml:"commit"`
+	Hash     string               `toml:"hash"`
+	Requires []config.Requirement `toml:"requires"`
+}
+
+type Lockfile struct {
+	// Requires is the set of direct project requirements that the lockfile was generated from
+	Requires []config.Requirement `toml:"requires"`
+	Packages []Package            `toml:"package"`
+}
+
+// FromFile reads the lockfile from the given directory. If the directory does not contain a lockfile
+// then the returned lockfile will be nil.
+func FromFile(directory string) (*Lockfile, error) {
+	contents, err := os.ReadFile(filepath.Join(directory, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lockfile{}
+	if err = toml.Unmarshal(contents, lock); err != nil {
+		return nil, err
+	}
+
+	return lock, nil
+}
+
+func ToFile(directory string, lock *Lockfile) error {
+	slices.SortFunc(lock.Packages, func(a, b Package) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	marshalled, err := toml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+
+	content := "# This file is generated by proofman - do not edit it manually\n\n" + string(marshalled)
+	return internal.WriteFile(filepath.Join(directory, FileName), content, false)
+}
+
+// Package returns the locked package with the given name, or nil if the package is not locked.
+func (l *Lockfile) Package(name string) *Package {
+	for i := range l.Packages {
+		if l.Packages[i].Name == name {
+			return &l.Packages[i]
+		}
+	}
+
+	return nil
+}
+
+// Diff compares the requirements the lockfile was generated from against the given project requirements. Each
+// line of the output is prefixed with '+' for requirements only present in the project, and '-' for requirements
+// only present in the lockfile. The output is empty if the two agree.
+func (l *Lockfile) Diff(requires []config.Requirement) []string {
+	locked, current := set.New[string](len(l.Requires)), set.New[string](len(requires))
+	for _, req := range l.Requires {
+		locked.Insert(req.String())
+	}
+	for _, req := range requires {
+		current.Insert(req.String())
+	}
+
+	lines := make([]string, 0)
+	for _, req := range slices.Sorted(current.Difference(locked).Items()) {
+		lines = append(lines, "+ "+req)
+	}
+	for _, req := range slices.Sorted(locked.Difference(current).Items()) {
+		lines = append(lines, "- "+req)
+	}
+
+	return lines
+}
+
+// Verify checks that the lockfile was generated from the given project requirements, returning an error
+// describing the differences if it was not.
+func (l *Lockfile) Verify(requires []config.Requirement) error {
+	diff := l.Diff(requires)
+	if len(diff) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w\n  %s", ErrOutOfDate, strings.Join(diff, "\n  "))
+}
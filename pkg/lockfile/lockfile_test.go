@@ -0,0 +1,75 @@
+package lockfile
+
+import (
+	"errors"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/pkg/config"
+	"testing"
+)
+
+func requirements(raw ...string) []config.Requirement {
+	reqs := make([]config.Requirement, 0)
+	for _, r := range raw {
+		req, err := config.ParseRequirement(r)
+		if err != nil {
+			panic(err)
+		}
+		reqs = append(reqs, req)
+	}
+
+	return reqs
+}
+
+func TestDiffAndVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		locked   []string
+		current  []string
+		expected []string
+	}{
+		{
+			name:     "unchanged",
+			locked:   []string{"A @ 2024-05-12", "B"},
+			current:  []string{"B", "A @ 2024-05-12"},
+			expected: []string{},
+		},
+		{
+			name:     "changed",
+			locked:   []string{"A @ 2023-09-11", "B"},
+			current:  []string{"A @ 2024-05-12", "B"},
+			expected: []string{"+ A @ 2024-05-12", "- A @ 2023-09-11"},
+		},
+		{
+			name:     "added",
+			locked:   []string{"A"},
+			current:  []string{"A", "C @ >=2024-01-01", "B"},
+			expected: []string{"+ B", "+ C @ >=2024-01-01"},
+		},
+		{
+			name:     "removed",
+			locked:   []string{"A", "B"},
+			current:  []string{},
+			expected: []string{"- A", "- B"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := asrt.New(t)
+
+			lock := &Lockfile{Requires: requirements(test.locked...)}
+			assert.Equal(test.expected, lock.Diff(requirements(test.current...)))
+
+			err := lock.Verify(requirements(test.current...))
+			if len(test.expected) == 0 {
+				assert.NoError(err)
+				return
+			}
+
+			assert.True(errors.Is(err, ErrOutOfDate))
+			for _, line := range test.expected {
+				assert.Contains(err.Error(), "\n  "+line)
+			}
+		})
+	}
+}
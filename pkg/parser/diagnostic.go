@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"fmt"
+	tks "github.com/tandemdude/proofman/pkg/parser/tokens"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Diagnostic is an error at a position within a lexed or parsed source. The error message renders the line of the
+// source containing the error, with a caret underlining the offending token.
+type Diagnostic struct {
+	// File is the name of the file containing the error, which may be empty
+	File string
+	// Offset is the byte offset of the error within the source
+	Offset int
+	// Line and Column are the 1-based position of the error. Columns are counted in characters
+	Line   int
+	Column int
+	// Length is the number of characters to underline
+	Length  int
+	Message string
+	// SourceLine is the line of the source containing the error, which is empty if the source is not known
+	SourceLine string
+	// Session is the name of the session definition that was skipped because of the error, which is empty if the
+	// error is not within a named session definition. It is only set when parsing recovers from errors
+	Session string
+}
+
+// sourceLine returns the line of the given source containing the given offset, alongside the offset of its start.
+func sourceLine(source string, offset int) (string, int) {
+	offset = min(offset, len(source))
+
+	start := strings.LastIndex(source[:offset], "\n") + 1
+	end := strings.Index(source[start:], "\n")
+	if end == -1 {
+		end = len(source) - start
+	}
+
+	return strings.TrimSuffix(source[start:start+end], "\r"), start
+}
+
+// newDiagnostic creates a diagnostic for the given number of bytes at the given offset of the source.
+func newDiagnostic(file, source string, offset, length int, message string) *Diagnostic {
+	line, start := sourceLine(source, offset)
+	offset = min(offset, len(source))
+
+	return &Diagnostic{
+		File:       file,
+		Offset:     offset,
+		Line:       strings.Count(source[:start], "\n") + 1,
+		Column:     utf8.RuneCountInString(source[start:offset]) + 1,
+		Length:     max(utf8.RuneCountInString(source[offset:min(offset+length, len(source))]), 1),
+		Message:    message,
+		SourceLine: line,
+	}
+}
+
+// tokenDiagnostic creates a diagnostic underlining the given token. The source may be empty if it is not known.
+func tokenDiagnostic(source string, token *tks.Token, message string) *Diagnostic {
+	diagnostic := &Diagnostic{
+		File:    token.File,
+		Offset:  token.Offset,
+		Line:    token.Line,
+		Column:  token.Column,
+		Length:  max(utf8.RuneCountInString(token.Raw), 1),
+		Message: message,
+	}
+	if source != "" {
+		diagnostic.SourceLine, _ = sourceLine(source, token.Offset)
+	}
+
+	return diagnostic
+}
+
+// Position returns the position of the diagnostic formatted as 'file:line:column'.
+func (d *Diagnostic) Position() string {
+	if d.File == "" {
+		return fmt.Sprintf("%d:%d", d.Line, d.Column)
+	}
+
+	return fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+}
+
+func (d *Diagnostic) Error() string {
+	var builder strings.Builder
+
+	gutter := strings.Repeat(" ", len(strconv.Itoa(d.Line)))
+	builder.WriteString("error: " + d.Message + "\n")
+	builder.WriteString(gutter + "--> " + d.Position())
+	if d.SourceLine == "" {
+		return builder.String()
+	}
+
+	// tabs before the caret are kept so that it lines up with the source line
+	line := []rune(d.SourceLine)
+	padding := make([]rune, 0, d.Column-1)
+	for _, r := range line[:min(d.Column-1, len(line))] {
+		if r == '\t' {
+			padding = append(padding, '\t')
+		} else {
+			padding = append(padding, ' ')
+		}
+	}
+	carets := strings.Repeat("^", max(min(d.Length, len(line)-len(padding)), 1))
+
+	builder.WriteString("\n" + gutter + " |\n")
+	builder.WriteString(strconv.Itoa(d.Line) + " | " + d.SourceLine + "\n")
+	builder.WriteString(gutter + " | " + string(padding) + carets)
+
+	return builder.String()
+}
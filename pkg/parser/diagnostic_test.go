@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"errors"
+	asrt "github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestLexerPositions(t *testing.T) {
+	assert := asrt.New(t)
+
+	tokens, err := NewFileLexer("ROOT", "session \"Foo\n\" =\n\tHOL + (* c *) theories Foo").Split()
+	assert.NoError(err)
+
+	positions := make([][3]int, 0)
+	for _, token := range tokens {
+		assert.Equal("ROOT", token.File)
+		positions = append(positions, [3]int{token.Offset, token.Line, token.Column})
+	}
+	assert.Equal([][3]int{{0, 1, 1}, {8, 1, 9}, {15, 2, 3}, {18, 3, 2}, {22, 3, 6}, {24, 3, 8}, {32, 3, 16}, {41, 3, 25}}, positions)
+}
+
+func TestDiagnosticRendering(t *testing.T) {
+	assert := asrt.New(t)
+
+	source := "session Foo = HOL +\n  options [timeout = 600\n\ttheories Foo\n"
+	_, err := ParseNamedRootFile("thys/Foo/ROOT", strings.NewReader(source))
+
+	var diagnostic *Diagnostic
+	assert.True(errors.As(err, &diagnostic))
+	assert.Equal(3, diagnostic.Line)
+	assert.Equal(2, diagnostic.Column)
+	assert.Equal(
+		"error: expected ',', ']', found Identifier theories\n"+
+			" --> thys/Foo/ROOT:3:2\n"+
+			"  |\n"+
+			"3 | \ttheories Foo\n"+
+			"  | \t^^^^^^^^",
+		err.Error(),
+	)
+
+	_, err = ParseRootFile(strings.NewReader("session Foo = HOL +\n  options"))
+	assert.Equal(
+		"error: expected '[', found end of file\n"+
+			" --> 2:10\n"+
+			"  |\n"+
+			"2 |   options\n"+
+			"  |          ^",
+		err.Error(),
+	)
+
+	_, err = ParseRootFile(strings.NewReader("session Foo = HOL +\n  theories \"Foo"))
+	assert.True(errors.As(err, &diagnostic))
+	assert.Equal("unterminated string literal", diagnostic.Message)
+	assert.Equal("2:12", diagnostic.Position())
+}
+
+func TestTheoryFilePositions(t *testing.T) {
+	assert := asrt.New(t)
+
+	source := "(* preamble *)\n\ntheory Foo\n  imports Main\nbegin\nend\n\ntheory Bar\n  imports Main =\nbegin\n"
+	_, err := ParseNamedTheoryFile("Foo.thy", strings.NewReader(source))
+
+	var diagnostic *Diagnostic
+	assert.True(errors.As(err, &diagnostic))
+	assert.Equal("Foo.thy:9:16", diagnostic.Position())
+	assert.Equal("  imports Main =", diagnostic.SourceLine)
+
+	_, err = ParseTheoryFile(strings.NewReader(source))
+	assert.True(errors.As(err, &diagnostic))
+	assert.Equal("9:16", diagnostic.Position())
+}
@@ -1,46 +1,145 @@
 package parser
 
 import (
+	"errors"
 	"github.com/tandemdude/proofman/pkg/parser/structure"
+	tks "github.com/tandemdude/proofman/pkg/parser/tokens"
 	"io"
 	"regexp"
+	"slices"
+	"strings"
 )
 
 func ParseRootFile(reader io.Reader) (*structure.RootStructure, error) {
+	return ParseNamedRootFile("", reader)
+}
+
+// ParseNamedRootFile parses the given ROOT file. The name of the file is used within the positions of tokens,
+// and of any returned Diagnostic.
+func ParseNamedRootFile(name string, reader io.Reader) (*structure.RootStructure, error) {
 	content, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
 
-	lexer := NewLexer(string(content))
-	tokens, err := lexer.Split()
+	_, parsed, err := parseRoot(name, string(content))
+	return parsed, err
+}
+
+// ParseRootFileRecovering parses the given ROOT file without stopping at the first error, see
+// RootParser.ParseRecovering. Lexing resumes from the next line starting with a top level keyword after an error, so
+// only the construct containing the error is skipped. The returned error is only non-nil if the file could not be read.
+func ParseRootFileRecovering(name string, reader io.Reader) (*structure.RootStructure, []*Diagnostic, error) {
+	content, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	source := string(content)
+
+	tokens, diagnostics := make([]*tks.Token, 0), make([]*Diagnostic, 0)
 
-	return NewRootParser(tokens).Parse()
+	lexer := NewFileLexer(name, source)
+	for offset := 0; offset < len(source); {
+		split, err := lexer.SplitFrom(offset)
+		if err == nil {
+			tokens = append(tokens, split...)
+			break
+		}
+
+		var diagnostic *Diagnostic
+		if !errors.As(err, &diagnostic) {
+			diagnostic = newDiagnostic(name, source, offset, 0, err.Error())
+		}
+		diagnostics = append(diagnostics, diagnostic)
+
+		// the construct containing the lexer error is incomplete, so parse only the constructs before it
+		for i := len(split) - 1; i >= 0; i-- {
+			if split[i].Type == tks.Identifier && slices.Contains(topLevelKeywords, split[i].Value) {
+				diagnostic.Session = sessionName(split, i)
+				split = split[:i]
+				break
+			}
+		}
+		tokens = append(tokens, split...)
+
+		offset = nextTopLevelKeyword(source, diagnostic.Offset)
+	}
+
+	rootParser := NewRootParser(tokens)
+	rootParser.source = source
+
+	parsed, parseDiagnostics := rootParser.ParseRecovering()
+	diagnostics = append(parseDiagnostics, diagnostics...)
+	slices.SortStableFunc(diagnostics, func(a, b *Diagnostic) int { return a.Offset - b.Offset })
+
+	return parsed, diagnostics, nil
+}
+
+var topLevelKeywordLine = regexp.MustCompile(`(?m)^[ \t]*(` + strings.Join(topLevelKeywords, "|") + `)\b`)
+
+// nextTopLevelKeyword returns the offset of the next top level keyword at the start of a line, searching from the
+// line after the one containing the given offset. The length of the source is returned if there are no more top
+// level keywords.
+func nextTopLevelKeyword(source string, offset int) int {
+	newline := strings.IndexByte(source[min(offset, len(source)):], '\n')
+	if newline == -1 {
+		return len(source)
+	}
+	offset += newline + 1
+
+	match := topLevelKeywordLine.FindStringSubmatchIndex(source[offset:])
+	if match == nil {
+		return len(source)
+	}
+
+	return offset + match[2]
+}
+
+// parseRoot lexes and parses the given ROOT source, returning the tokens alongside the parsed structure.
+func parseRoot(name, source string) ([]*tks.Token, *structure.RootStructure, error) {
+	tokens, err := NewFileLexer(name, source).Split()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rootParser := NewRootParser(tokens)
+	rootParser.source = source
+
+	parsed, err := rootParser.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, parsed, nil
 }
 
 var theoryBlock = regexp.MustCompile(`(?msU)^theory(.+)begin`)
 
 func ParseTheoryFile(reader io.Reader) ([]*structure.TheoryStructure, error) {
+	return ParseNamedTheoryFile("", reader)
+}
+
+// ParseNamedTheoryFile parses the headers of the theories within the given theory file. The name of the file is used
+// within the positions of tokens, and of any returned Diagnostic.
+func ParseNamedTheoryFile(name string, reader io.Reader) ([]*structure.TheoryStructure, error) {
 	content, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
-
-	stringContent := string(content)
-	parsedQueries := theoryBlock.FindAllString(stringContent, -1)
+	source := string(content)
 
 	output := make([]*structure.TheoryStructure, 0)
-	for _, query := range parsedQueries {
-		lexer := NewLexer(query)
-		tokens, err := lexer.Split()
+	for _, match := range theoryBlock.FindAllStringIndex(source, -1) {
+		// the lexer is limited to the end of the header, but positions remain relative to the start of the file
+		tokens, err := NewFileLexer(name, source[:match[1]]).SplitFrom(match[0])
 		if err != nil {
 			return nil, err
 		}
 
-		parsed, err := NewTheoryParser(tokens).Parse()
+		theoryParser := NewTheoryParser(tokens)
+		theoryParser.source = source
+
+		parsed, err := theoryParser.Parse()
 		if err != nil {
 			return nil, err
 		}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 var runeTokenMap = map[rune]tk.TokenType{
@@ -22,6 +23,7 @@ var runeTokenMap = map[rune]tk.TokenType{
 }
 
 type Lexer struct {
+	file   string
 	source string
 }
 
@@ -29,6 +31,12 @@ func NewLexer(source string) *Lexer {
 	return &Lexer{source: source}
 }
 
+// NewFileLexer creates a lexer for the source of the given file. The file name is recorded within the position
+// of each token.
+func NewFileLexer(file, source string) *Lexer {
+	return &Lexer{file: file, source: source}
+}
+
 func parseIdentifier(s *string, idx int) (string, error) {
 	start, dotFound := idx, false
 	for idx < len(*s) {
@@ -191,17 +199,55 @@ func parseComment(s *string, idx int) (string, error) {
 	return "", errors.New("unterminated comment")
 }
 
+// token creates a token spanning the source [start, end) at the given position.
+func (l *Lexer) token(tokenType tk.TokenType, value string, start, end, line, lineStart int) *tk.Token {
+	return &tk.Token{
+		Type:   tokenType,
+		Value:  value,
+		File:   l.file,
+		Offset: start,
+		Line:   line,
+		Column: utf8.RuneCountInString(l.source[lineStart:start]) + 1,
+		Raw:    l.source[start:end],
+	}
+}
+
 func (l *Lexer) Split() ([]*tk.Token, error) {
+	return l.SplitFrom(0)
+}
+
+// SplitFrom lexes the source starting at the given byte offset. The positions of the tokens are relative to the
+// start of the full source.
+func (l *Lexer) SplitFrom(offset int) ([]*tk.Token, error) {
 	tokens := make([]*tk.Token, 0)
 
-	currentIndex, lineNo := 0, 0
+	// line is 1-based, lineStart is the offset of the first character of the current line
+	currentIndex := min(offset, len(l.source))
+	line := strings.Count(l.source[:currentIndex], "\n") + 1
+	lineStart := strings.LastIndex(l.source[:currentIndex], "\n") + 1
+	emit := func(tokenType tk.TokenType, value string, end int) {
+		token := l.token(tokenType, value, currentIndex, end, line, lineStart)
+		tokens = append(tokens, token)
+
+		// string literals and comments may span multiple lines
+		if newline := strings.LastIndex(token.Raw, "\n"); newline != -1 {
+			line += strings.Count(token.Raw, "\n")
+			lineStart = currentIndex + newline + 1
+		}
+		currentIndex = end
+	}
+	fail := func(err error) ([]*tk.Token, error) {
+		return tokens, newDiagnostic(l.file, l.source, currentIndex, 1, err.Error())
+	}
+
 	for currentIndex < len(l.source) {
 		currentRune := rune(l.source[currentIndex])
 
 		// Ignore whitespace
 		if unicode.IsSpace(currentRune) {
 			if currentRune == '\n' {
-				lineNo++
+				line++
+				lineStart = currentIndex + 1
 			}
 
 			currentIndex++
@@ -213,73 +259,58 @@ func (l *Lexer) Split() ([]*tk.Token, error) {
 		case unicode.IsLetter(currentRune):
 			str, err := parseIdentifier(&l.source, currentIndex)
 			if err != nil {
-				return tokens, err
+				return fail(err)
 			}
 
-			tokens = append(tokens, &tk.Token{tk.Identifier, str, lineNo})
-			currentIndex += len(str)
+			emit(tk.Identifier, str, currentIndex+len(str))
 		case currentRune == '"':
 			str, err := parseStringLiteral(&l.source, currentIndex)
 			if err != nil {
-				return tokens, err
+				return fail(err)
 			}
 
-			tokens = append(tokens, &tk.Token{tk.StringLiteral, strings.TrimSpace(str), lineNo})
-			currentIndex += len(str) + 2
-
-			lineNo += strings.Count(str, "\n")
+			emit(tk.StringLiteral, strings.TrimSpace(str), currentIndex+len(str)+2)
 		case currentRune == '{' && currentIndex+1 < len(l.source) && l.source[currentIndex+1] == '*':
 			str, err := parseBracedStringLiteral(&l.source, currentIndex)
 			if err != nil {
-				return tokens, err
+				return fail(err)
 			}
 
-			tokens = append(tokens, &tk.Token{tk.StringLiteral, strings.TrimSpace(str), lineNo})
-			currentIndex += len(str) + 4
-
-			lineNo += strings.Count(str, "\n")
+			emit(tk.StringLiteral, strings.TrimSpace(str), currentIndex+len(str)+4)
 		case currentRune == '\\':
 			str, err := parseLatexStringLiteral(&l.source, currentIndex)
 			if err != nil {
-				return tokens, err
+				return fail(err)
 			}
 
 			// TODO - consider adding a token info flag mentioning this is latex syntax
 			// if the string starts with `\<comment>` then this is a comment instead of a string literal
 			if strings.HasPrefix(str, `\<comment>`) {
-				tokens = append(tokens, &tk.Token{tk.Comment, str, lineNo})
+				emit(tk.Comment, str, currentIndex+len(str))
 			} else {
-				tokens = append(tokens, &tk.Token{tk.StringLiteral, str, lineNo})
+				emit(tk.StringLiteral, str, currentIndex+len(str))
 			}
-			currentIndex += len(str)
-
-			lineNo += strings.Count(str, "\n")
 		case unicode.IsDigit(currentRune):
 			str, err := parseNumberLiteral(&l.source, currentIndex)
 			if err != nil {
-				return tokens, err
+				return fail(err)
 			}
 
-			tokens = append(tokens, &tk.Token{tk.NumberLiteral, str, lineNo})
-			currentIndex += len(str)
+			emit(tk.NumberLiteral, str, currentIndex+len(str))
 		case currentRune == '(' && currentIndex+1 < len(l.source) && l.source[currentIndex+1] == '*':
 			str, err := parseComment(&l.source, currentIndex)
 			if err != nil {
-				return tokens, err
+				return fail(err)
 			}
 
-			tokens = append(tokens, &tk.Token{tk.Comment, strings.TrimSpace(str), lineNo})
-			currentIndex += len(str) + 4
-
-			lineNo += strings.Count(str, "\n")
+			emit(tk.Comment, strings.TrimSpace(str), currentIndex+len(str)+4)
 		default:
 			tokenType, ok := runeTokenMap[currentRune]
 			if !ok {
-				return tokens, fmt.Errorf("unknown token type for rune %s", strconv.QuoteRune(currentRune))
+				return fail(fmt.Errorf("unknown token type for rune %s", strconv.QuoteRune(currentRune)))
 			}
 
-			tokens = append(tokens, &tk.Token{tokenType, strconv.QuoteRune(currentRune), lineNo})
-			currentIndex++
+			emit(tokenType, strconv.QuoteRune(currentRune), currentIndex+1)
 		}
 	}
 
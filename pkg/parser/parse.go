@@ -10,6 +10,8 @@ import (
 type Parser struct {
 	tokens       []*tks.Token
 	currentIndex int
+	// source is the lexed source of the tokens, used to render diagnostics. It may be empty.
+	source string
 }
 
 /**********
@@ -36,45 +38,78 @@ func (p *Parser) peekNext() *tks.Token {
 	return p.peek(1)
 }
 
-func (p *Parser) eat(tokenTypes ...tks.TokenType) (*tks.Token, error) {
-	reprs := make([]string, 0)
-	for _, tokenType := range tokenTypes {
-		reprs = append(reprs, tks.TokenTypeName[tokenType])
+// describe returns a description of the given token for use within diagnostics.
+func describe(token *tks.Token) string {
+	switch token.Type {
+	case tks.Identifier, tks.StringLiteral, tks.NumberLiteral:
+		return fmt.Sprintf("%s %s", tks.TokenTypeName[token.Type], token.Raw)
+	default:
+		return tks.TokenTypeName[token.Type]
 	}
+}
+
+// errorAt returns a diagnostic underlining the given token.
+func (p *Parser) errorAt(token *tks.Token, format string, args ...any) *Diagnostic {
+	return tokenDiagnostic(p.source, token, fmt.Sprintf(format, args...))
+}
+
+// errorAtEOF returns a diagnostic positioned directly after the final token.
+func (p *Parser) errorAtEOF(format string, args ...any) *Diagnostic {
+	message := fmt.Sprintf(format, args...)
+	if len(p.tokens) == 0 {
+		return newDiagnostic("", p.source, 0, 0, message)
+	}
+
+	last := p.tokens[len(p.tokens)-1]
+	if p.source != "" {
+		return newDiagnostic(last.File, p.source, last.Offset+len(last.Raw), 0, message)
+	}
+
+	diagnostic := tokenDiagnostic(p.source, last, message)
+	diagnostic.Offset += len(last.Raw)
+	diagnostic.Column += diagnostic.Length
+	diagnostic.Length = 1
+	return diagnostic
+}
 
+// expected returns a diagnostic stating that the given constructs were expected at the parse position.
+func (p *Parser) expected(expected string) *Diagnostic {
 	next := p.current()
 	if next == nil {
-		return nil, fmt.Errorf("Parsing failed at EOF\nExpected: %s\nGot: EOF", strings.Join(reprs, ", "))
+		return p.errorAtEOF("expected %s, found end of file", expected)
 	}
 
+	return p.errorAt(next, "expected %s, found %s", expected, describe(next))
+}
+
+func (p *Parser) eat(tokenTypes ...tks.TokenType) (*tks.Token, error) {
+	next := p.current()
+	if next != nil && slices.Contains(tokenTypes, next.Type) {
+		p.currentIndex++
+		return next, nil
+	}
+
+	reprs := make([]string, 0)
 	for _, tokenType := range tokenTypes {
-		if next.Type == tokenType {
-			p.currentIndex++
-			return next, nil
-		}
+		reprs = append(reprs, tks.TokenTypeName[tokenType])
 	}
 
-	return nil, fmt.Errorf("Parsing failed at L%d\nExpected: %s\nGot: %s",
-		next.LineNo, strings.Join(reprs, ", "), tks.TokenTypeName[next.Type],
-	)
+	return nil, p.expected(strings.Join(reprs, ", "))
 }
 
 func (p *Parser) eatKeyword(keywords ...string) (*tks.Token, error) {
-	identifier, err := p.eat(tks.Identifier)
-	if err != nil {
-		return nil, err
+	next := p.current()
+	if next != nil && next.Type == tks.Identifier && slices.Contains(keywords, next.Value) {
+		p.currentIndex++
+		return next, nil
 	}
 
+	reprs := make([]string, 0)
 	for _, keyword := range keywords {
-		if identifier.Value == keyword {
-			return identifier, nil
-		}
+		reprs = append(reprs, "'"+keyword+"'")
 	}
 
-	p.currentIndex--
-	return nil, fmt.Errorf("Parsing failed at L%d\nExpected: %s\nGot: %s",
-		identifier.LineNo, strings.Join(keywords, ", "), identifier.Value,
-	)
+	return nil, p.expected(strings.Join(reprs, ", "))
 }
 
 func (p *Parser) stringArray(terminators []string) ([]string, error) {
@@ -0,0 +1,519 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/tandemdude/proofman/pkg/parser/structure"
+	tks "github.com/tandemdude/proofman/pkg/parser/tokens"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnknownSession   = errors.New("session is not defined")
+	ErrDuplicateSession = errors.New("session is already defined")
+	ErrInvalidEdit      = errors.New("edit produced an invalid ROOT file")
+)
+
+const defaultIndent = "  "
+
+// RootEditor applies edits to a ROOT file. Edits are made to the lossless syntax tree of the file, so any
+// comments and formatting that are not touched by an edit are preserved.
+type RootEditor struct {
+	syntax    *RootSyntax
+	structure *structure.RootStructure
+}
+
+// NewRootEditor parses the given ROOT file for editing.
+func NewRootEditor(reader io.Reader) (*RootEditor, error) {
+	syntax, err := ParseRootSyntax(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRootEditor(syntax)
+}
+
+func newRootEditor(syntax *RootSyntax) (*RootEditor, error) {
+	parsed, err := syntax.Structure()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RootEditor{syntax: syntax, structure: parsed}, nil
+}
+
+// Structure returns the structure of the edited ROOT file. The returned value must not be modified.
+func (e *RootEditor) Structure() *structure.RootStructure {
+	return e.structure
+}
+
+// String returns the edited ROOT file.
+func (e *RootEditor) String() string {
+	return e.syntax.String()
+}
+
+/**********
+ * HELPERS
+ **********/
+
+// formatName formats the given value as a name within a ROOT file, quoting it unless it can be written as
+// a bare identifier.
+func formatName(value string) string {
+	if value != "" && unicode.IsLetter(rune(value[0])) && !slices.Contains(allkeywords, value) {
+		if identifier, err := parseIdentifier(&value, 0); err == nil && identifier == value {
+			return value
+		}
+	}
+
+	return `"` + value + `"`
+}
+
+// formatValue formats the given value as the value of an option, quoting it unless it can be written as a
+// bare identifier or number.
+func formatValue(value string) string {
+	if value != "" && unicode.IsDigit(rune(value[0])) {
+		if number, err := parseNumberLiteral(&value, 0); err == nil && number == value {
+			return value
+		}
+	}
+
+	return formatName(value)
+}
+
+// formatDescription formats the given description, which may already be a cartouche.
+func formatDescription(description string) string {
+	if strings.HasPrefix(description, `\<open>`) {
+		return description
+	}
+
+	return `"` + description + `"`
+}
+
+// formatOptions formats the given options map as an options list.
+func formatOptions(options map[string]*tks.Token) string {
+	formatted := make([]string, 0, len(options))
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		option := options[name]
+		switch {
+		case option == nil:
+			formatted = append(formatted, formatName(name))
+		case option.Raw != "":
+			formatted = append(formatted, formatName(name)+" = "+option.Raw)
+		case option.Type == tks.StringLiteral:
+			formatted = append(formatted, formatName(name)+` = "`+option.Value+`"`)
+		default:
+			formatted = append(formatted, formatName(name)+" = "+option.Value)
+		}
+	}
+
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
+
+// formatSession formats the given session as a session definition.
+func formatSession(session *structure.Session) string {
+	var builder strings.Builder
+
+	section := func(header string, entries []string) {
+		builder.WriteString("\n" + defaultIndent + header)
+		for _, entry := range entries {
+			builder.WriteString("\n" + defaultIndent + defaultIndent + entry)
+		}
+	}
+	names := func(values []string) []string {
+		formatted := make([]string, 0, len(values))
+		for _, value := range values {
+			formatted = append(formatted, formatName(value))
+		}
+		return formatted
+	}
+
+	builder.WriteString(Session + " " + formatName(session.Name))
+	if len(session.Groups) > 0 {
+		builder.WriteString(" (" + strings.Join(names(session.Groups), " ") + ")")
+	}
+	if session.Dir != "" {
+		builder.WriteString(" " + In + " " + formatName(session.Dir))
+	}
+	builder.WriteString(" =")
+	if session.SystemName != "" {
+		builder.WriteString(" " + formatName(session.SystemName) + " +")
+	}
+
+	if session.Description != "" {
+		section(Description+" "+formatDescription(session.Description), nil)
+	}
+	if len(session.Options) > 0 {
+		section(Options+" "+formatOptions(session.Options), nil)
+	}
+	if len(session.Sessions) > 0 {
+		section(Sessions, names(session.Sessions))
+	}
+	if len(session.Directories) > 0 {
+		section(Directories, names(session.Directories))
+	}
+	for _, theories := range session.Theories {
+		header := Theories
+		if len(theories.Options) > 0 {
+			header += " " + formatOptions(theories.Options)
+		}
+
+		entries := make([]string, 0, len(theories.Entries))
+		for _, entry := range theories.Entries {
+			if theories.IsGlobal[entry] {
+				entries = append(entries, formatName(entry)+" ("+Global+")")
+			} else {
+				entries = append(entries, formatName(entry))
+			}
+		}
+		section(header, entries)
+	}
+	if len(session.DocumentTheories) > 0 {
+		section(DocumentTheories, names(session.DocumentTheories))
+	}
+	for _, documentFiles := range session.DocumentFiles {
+		header := DocumentFiles
+		if documentFiles.Dir != "" {
+			header += " (" + In + " " + formatName(documentFiles.Dir) + ")"
+		}
+		section(header, names(documentFiles.Entries))
+	}
+	for _, exportFiles := range session.ExportFiles {
+		header := ExportFiles
+		if exportFiles.Dir != "" {
+			header += " (" + In + " " + formatName(exportFiles.Dir) + ")"
+		}
+		if exportFiles.Nat != "" {
+			header += " [" + exportFiles.Nat + "]"
+		}
+		section(header, names(exportFiles.Entries))
+	}
+	if len(session.ExportClasspath) > 0 {
+		section(ExportClasspath, names(session.ExportClasspath))
+	}
+
+	return builder.String()
+}
+
+// indentOf returns the indentation of the given token, or an empty string if it does not start a line.
+func (e *RootEditor) indentOf(idx int) string {
+	leading := e.syntax.Tokens[idx].Leading
+	if !strings.Contains(leading, "\n") {
+		return ""
+	}
+
+	return leading[strings.LastIndex(leading, "\n")+1:]
+}
+
+// after returns the offset directly after the given token, extended past any comments that follow it on the
+// same line.
+func (e *RootEditor) after(idx int) int {
+	tokens := e.syntax.Tokens
+	for idx+1 < len(tokens) && tokens[idx+1].Type == tks.Comment && !strings.Contains(tokens[idx+1].Leading, "\n") {
+		idx++
+	}
+
+	return tokens[idx].Offset + len(tokens[idx].Raw)
+}
+
+// before returns the offset at which to insert text in front of the given token. Any comments on their own lines
+// directly preceding the token are kept attached to it.
+func (e *RootEditor) before(idx int) int {
+	tokens := e.syntax.Tokens
+	for idx > 0 && tokens[idx-1].Type == tks.Comment && strings.Contains(tokens[idx-1].Leading, "\n") {
+		idx--
+	}
+
+	if idx == 0 {
+		return 0
+	}
+	return tokens[idx-1].Offset + len(tokens[idx-1].Raw)
+}
+
+// replace replaces the source between the given offsets with the given text, and parses the result.
+func (e *RootEditor) replace(start, end int, text string) error {
+	source := e.syntax.String()
+
+	syntax, err := parseRootSyntax(source[:start] + text + source[end:])
+	if err != nil {
+		return errors.Join(ErrInvalidEdit, err)
+	}
+	parsed, err := syntax.Structure()
+	if err != nil {
+		return errors.Join(ErrInvalidEdit, err)
+	}
+
+	e.syntax, e.structure = syntax, parsed
+	return nil
+}
+
+func (e *RootEditor) insert(offset int, text string) error {
+	return e.replace(offset, offset, text)
+}
+
+// session returns the syntax node and structure of the session with the given name.
+func (e *RootEditor) session(name string) (*SyntaxNode, *structure.Session, error) {
+	node := e.syntax.Session(name)
+	if node == nil {
+		return nil, nil, fmt.Errorf("%w - %s", ErrUnknownSession, name)
+	}
+
+	for _, chapter := range e.structure.Chapters {
+		for _, session := range chapter.Sessions {
+			if session.Name == name {
+				return node, session, nil
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w - %s", ErrUnknownSession, name)
+}
+
+// sectionIndent returns the indentation used for the sections of the given session.
+func (e *RootEditor) sectionIndent(node *SyntaxNode) string {
+	for _, section := range node.Children {
+		if indent := e.indentOf(section.Start); indent != "" {
+			return indent
+		}
+	}
+
+	return defaultIndent
+}
+
+// sectionEntries returns the indices of the entry tokens of the given section - tokens within a qualifier such
+// as '(global)' or '(in dir)' and the options of a 'theories' section are excluded.
+func (e *RootEditor) sectionEntries(section *SyntaxNode) []int {
+	entries := make([]int, 0)
+
+	depth := 0
+	for i := nextToken(e.syntax.Tokens, section.Start); i < section.End; i = nextToken(e.syntax.Tokens, i) {
+		switch token := e.syntax.Tokens[i]; token.Type {
+		case tks.LeftParen, tks.LeftSquareParen:
+			depth++
+		case tks.RightParen, tks.RightSquareParen:
+			depth--
+		case tks.Identifier, tks.StringLiteral:
+			if depth == 0 {
+				entries = append(entries, i)
+			}
+		default:
+		}
+	}
+
+	return entries
+}
+
+// appendEntries appends the given formatted entries to the end of the given section, using the same layout as
+// the existing entries of the section.
+func (e *RootEditor) appendEntries(session, section *SyntaxNode, entries []string) error {
+	separator := "\n" + e.sectionIndent(session) + defaultIndent
+	if existing := e.sectionEntries(section); len(existing) > 0 {
+		last := existing[len(existing)-1]
+		if indent := e.indentOf(last); indent != "" {
+			separator = "\n" + indent
+		} else {
+			separator = " "
+		}
+	}
+
+	return e.insert(e.after(section.End-1), separator+strings.Join(entries, separator))
+}
+
+// insertSection inserts a new section into the given session, at the position required by the grammar.
+func (e *RootEditor) insertSection(session *SyntaxNode, kind, qualifier string, entries []string) error {
+	indent := e.sectionIndent(session)
+
+	text := "\n" + indent + kind + qualifier
+	for _, entry := range entries {
+		text += "\n" + indent + defaultIndent + entry
+	}
+
+	rank := slices.Index(sectionKeywords, kind)
+	for _, section := range session.Children {
+		if slices.Index(sectionKeywords, section.Kind) > rank {
+			return e.insert(e.before(section.Start), text)
+		}
+	}
+
+	return e.insert(e.after(session.End-1), text)
+}
+
+/********
+ * EDITS
+ ********/
+
+// AddSession adds the given session to the end of the given chapter, adding the chapter to the end of the file
+// if it does not contain any sessions yet.
+func (e *RootEditor) AddSession(chapter string, session *structure.Session) error {
+	for _, c := range e.structure.Chapters {
+		for _, s := range c.Sessions {
+			if s.Name == session.Name {
+				return fmt.Errorf("%w - %s", ErrDuplicateSession, session.Name)
+			}
+		}
+	}
+
+	// the session is written in the canonical layout
+	syntax, err := parseRootSyntax(formatSession(session))
+	if err != nil {
+		return errors.Join(ErrInvalidEdit, err)
+	}
+	text := strings.TrimSuffix(syntax.Format(), "\n")
+
+	last, current, chapters := -1, "Unsorted", 0
+	for _, node := range e.syntax.Nodes {
+		if node.Kind == Chapter {
+			current = node.Name
+			chapters++
+		}
+
+		if current == chapter && node.Kind != ChapterDefinition {
+			last = node.End - 1
+		}
+	}
+	if last != -1 {
+		return e.insert(e.after(last), "\n\n"+text)
+	}
+
+	if chapter != "Unsorted" || chapters > 0 {
+		text = Chapter + " " + formatName(chapter) + "\n\n" + text
+	}
+
+	if len(e.syntax.Tokens) == 0 {
+		return e.replace(0, len(e.syntax.Trailing), text+"\n")
+	}
+	return e.insert(e.after(len(e.syntax.Tokens)-1), "\n\n"+text)
+}
+
+// AddSessionDependency adds the given dependency to the 'sessions' section of the given session, creating the
+// section if necessary. Nothing is changed if the session already depends on the dependency.
+func (e *RootEditor) AddSessionDependency(session, dependency string) error {
+	node, parsed, err := e.session(session)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(parsed.Sessions, dependency) {
+		return nil
+	}
+
+	for _, section := range node.Children {
+		if section.Kind == Sessions {
+			return e.appendEntries(node, section, []string{formatName(dependency)})
+		}
+	}
+
+	return e.insertSection(node, Sessions, "", []string{formatName(dependency)})
+}
+
+// AddTheories adds the given theories to the given session, optionally qualified as global. Theories are appended
+// to the last 'theories' section of the session without options, creating a new section if necessary. Theories
+// that the session already contains are skipped.
+func (e *RootEditor) AddTheories(session string, entries []string, global bool) error {
+	node, parsed, err := e.session(session)
+	if err != nil {
+		return err
+	}
+
+	formatted := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if slices.ContainsFunc(parsed.Theories, func(t *structure.Theories) bool { return slices.Contains(t.Entries, entry) }) {
+			continue
+		}
+
+		if global {
+			formatted = append(formatted, formatName(entry)+" ("+Global+")")
+		} else {
+			formatted = append(formatted, formatName(entry))
+		}
+	}
+	if len(formatted) == 0 {
+		return nil
+	}
+
+	// the theories sections of the structure are in the same order as the nodes
+	var target *SyntaxNode
+	theoriesIdx := 0
+	for _, section := range node.Children {
+		if section.Kind != Theories {
+			continue
+		}
+
+		if len(parsed.Theories[theoriesIdx].Options) == 0 {
+			target = section
+		}
+		theoriesIdx++
+	}
+
+	if target != nil {
+		return e.appendEntries(node, target, formatted)
+	}
+	return e.insertSection(node, Theories, "", formatted)
+}
+
+// SetOption sets the value of the given option within the 'options' section of the given session, creating the
+// section if necessary.
+func (e *RootEditor) SetOption(session, name, value string) error {
+	node, _, err := e.session(session)
+	if err != nil {
+		return err
+	}
+
+	tokens := e.syntax.Tokens
+	option := formatName(name) + " = " + formatValue(value)
+
+	var section *SyntaxNode
+	for _, s := range node.Children {
+		if s.Kind == Options {
+			section = s
+		}
+	}
+	if section == nil {
+		return e.insertSection(node, Options, " ["+option+"]", nil)
+	}
+
+	// find the option name, which always directly follows either the opening bracket or a comma
+	var names []int
+	previous := -1
+	for i := nextToken(tokens, section.Start); i < section.End; i = nextToken(tokens, i) {
+		if previous != -1 && (tokens[previous].Type == tks.LeftSquareParen || tokens[previous].Type == tks.Comma) {
+			names = append(names, i)
+		}
+		previous = i
+	}
+
+	for _, i := range names {
+		if tokens[i].Value != name {
+			continue
+		}
+
+		next := nextToken(tokens, i)
+		if tokens[next].Type != tks.Equal {
+			end := tokens[i].Offset + len(tokens[i].Raw)
+			return e.insert(end, " = "+formatValue(value))
+		}
+
+		valueToken := tokens[nextToken(tokens, next)]
+		return e.replace(valueToken.Offset, valueToken.Offset+len(valueToken.Raw), formatValue(value))
+	}
+
+	closing := section.End - 1
+	if len(names) == 0 {
+		return e.insert(tokens[closing].Offset, option)
+	}
+
+	// options laid out one per line are continued on a new line
+	separator := ", "
+	if strings.Contains(tokens[closing].Leading, "\n") {
+		separator = ",\n" + e.indentOf(names[0])
+	}
+
+	last := closing - 1
+	for tokens[last].Type == tks.Comment {
+		last--
+	}
+	return e.insert(tokens[last].Offset+len(tokens[last].Raw), separator+option)
+}
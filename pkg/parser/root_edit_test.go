@@ -0,0 +1,118 @@
+package parser
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/pkg/parser/structure"
+	"strings"
+	"testing"
+)
+
+const editTestRoot = `chapter AFP
+
+(* the main session *)
+session Foo (AFP) = HOL +
+  options [timeout = 600]
+  theories
+    Foo_Base (* base definitions *)
+  document_files
+    "root.tex"
+
+session Bar = Foo +
+  options [
+    timeout = 300,
+    document = pdf
+  ]
+  sessions "HOL-Library"
+  theories [document = false]
+    Bar
+`
+
+func newTestEditor(t *testing.T, source string) *RootEditor {
+	editor, err := NewRootEditor(strings.NewReader(source))
+	asrt.New(t).NoError(err)
+	return editor
+}
+
+// assertReparses checks that the edited file parses to the same structure as the editor reports.
+func assertReparses(t *testing.T, editor *RootEditor) {
+	assert := asrt.New(t)
+
+	reparsed, err := ParseRootFile(strings.NewReader(editor.String()))
+	assert.NoError(err)
+	assert.Equal(reparsed, editor.Structure())
+}
+
+func TestRootEditorAddSessionDependency(t *testing.T) {
+	assert := asrt.New(t)
+
+	editor := newTestEditor(t, editTestRoot)
+	assert.NoError(editor.AddSessionDependency("Foo", "HOL-Library"))
+	assert.NoError(editor.AddSessionDependency("Bar", "HOL-Algebra"))
+	assert.NoError(editor.AddSessionDependency("Bar", "HOL-Library"))
+	assert.ErrorIs(editor.AddSessionDependency("Baz", "HOL-Library"), ErrUnknownSession)
+
+	expected := strings.Replace(editTestRoot, "  options [timeout = 600]\n", "  options [timeout = 600]\n  sessions\n    \"HOL-Library\"\n", 1)
+	expected = strings.Replace(expected, `sessions "HOL-Library"`, `sessions "HOL-Library" "HOL-Algebra"`, 1)
+	assert.Equal(expected, editor.String())
+	assertReparses(t, editor)
+}
+
+func TestRootEditorAddTheories(t *testing.T) {
+	assert := asrt.New(t)
+
+	editor := newTestEditor(t, editTestRoot)
+	assert.NoError(editor.AddTheories("Foo", []string{"Foo_Base", "Foo", "HOL-Library.Foo"}, false))
+	assert.NoError(editor.AddTheories("Bar", []string{"Bar_Main"}, true))
+
+	expected := strings.Replace(editTestRoot, "(* base definitions *)\n", "(* base definitions *)\n    Foo\n    \"HOL-Library.Foo\"\n", 1)
+	expected = strings.Replace(expected, "    Bar\n", "    Bar\n  theories\n    Bar_Main (global)\n", 1)
+	assert.Equal(expected, editor.String())
+	assertReparses(t, editor)
+}
+
+func TestRootEditorSetOption(t *testing.T) {
+	assert := asrt.New(t)
+
+	editor := newTestEditor(t, editTestRoot)
+	assert.NoError(editor.SetOption("Foo", "timeout", "1200"))
+	assert.NoError(editor.SetOption("Foo", "quick_and_dirty", "true"))
+	assert.NoError(editor.SetOption("Bar", "document_output", "output dir"))
+
+	expected := strings.Replace(editTestRoot, "[timeout = 600]", "[timeout = 1200, quick_and_dirty = true]", 1)
+	expected = strings.Replace(expected, "    document = pdf\n", "    document = pdf,\n    document_output = \"output dir\"\n", 1)
+	assert.Equal(expected, editor.String())
+	assertReparses(t, editor)
+
+	editor = newTestEditor(t, "session Foo = HOL +\n  description \"Foo\"\n  theories Foo\n")
+	assert.NoError(editor.SetOption("Foo", "timeout", "60"))
+	assert.Equal("session Foo = HOL +\n  description \"Foo\"\n  options [timeout = 60]\n  theories Foo\n", editor.String())
+}
+
+func TestRootEditorAddSession(t *testing.T) {
+	assert := asrt.New(t)
+
+	session := &structure.Session{
+		Name:       "Baz",
+		SystemName: "HOL",
+		Sessions:   []string{"HOL-Library"},
+		Theories: []*structure.Theories{
+			{Entries: []string{"Baz", "Baz_Global"}, IsGlobal: map[string]bool{"Baz_Global": true}},
+		},
+	}
+
+	editor := newTestEditor(t, editTestRoot)
+	assert.NoError(editor.AddSession("AFP", session))
+	assert.ErrorIs(editor.AddSession("AFP", session), ErrDuplicateSession)
+	assert.Equal(
+		editTestRoot[:len(editTestRoot)-1]+"\n\nsession Baz = HOL +\n  sessions\n    \"HOL-Library\"\n  theories\n    Baz\n    Baz_Global (global)\n",
+		editor.String(),
+	)
+	assertReparses(t, editor)
+	assert.Equal(session.Theories[0].Entries, editor.Structure().Chapters["AFP"].Sessions[2].Theories[0].Entries)
+
+	editor = newTestEditor(t, "")
+	assert.NoError(editor.AddSession("Unsorted", &structure.Session{Name: "Baz", SystemName: "HOL"}))
+	assert.NoError(editor.AddSession("Examples", &structure.Session{Name: "Qux", SystemName: "Baz"}))
+	assert.Equal("session Baz = HOL +\n\nchapter Examples\n\nsession Qux = Baz +\n", editor.String())
+	assertReparses(t, editor)
+}
@@ -0,0 +1,273 @@
+package parser
+
+import (
+	tks "github.com/tandemdude/proofman/pkg/parser/tokens"
+	"io"
+	"strings"
+)
+
+// rootFormatter writes the tokens of a ROOT file in the canonical layout.
+type rootFormatter struct {
+	tokens  []*SyntaxToken
+	builder strings.Builder
+}
+
+// optionItem is a single entry within an options list.
+type optionItem struct {
+	name     string
+	value    string
+	leading  []string
+	trailing []string
+}
+
+// blankBefore returns whether the given token was preceded by a blank line in the original source.
+func blankBefore(token *SyntaxToken) bool {
+	return strings.Count(token.Leading, "\n") >= 2
+}
+
+func (f *rootFormatter) write(text string) {
+	f.builder.WriteString(text)
+}
+
+// line starts a new line with the given indentation and text.
+func (f *rootFormatter) line(indent, text string) {
+	if f.builder.Len() > 0 {
+		f.builder.WriteString("\n")
+	}
+	f.builder.WriteString(indent + text)
+}
+
+// comment writes the given comment. Comments that followed another token on the same line are kept at the end
+// of the current line, all other comments are written on their own line.
+func (f *rootFormatter) comment(token *SyntaxToken, indent string) {
+	if f.builder.Len() > 0 && !strings.Contains(token.Leading, "\n") {
+		f.write(" " + token.Raw)
+		return
+	}
+
+	f.line(indent, token.Raw)
+}
+
+// matching returns the index of the bracket closing the bracket at the given index.
+func (f *rootFormatter) matching(idx int) int {
+	depth := 0
+	for i := idx; i < len(f.tokens); i++ {
+		switch f.tokens[i].Type {
+		case tks.LeftParen, tks.LeftSquareParen:
+			depth++
+		case tks.RightParen, tks.RightSquareParen:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		default:
+		}
+	}
+
+	return len(f.tokens) - 1
+}
+
+// inline writes the tokens [from, to) onto the current line, separated by single spaces.
+func (f *rootFormatter) inline(from, to int) {
+	for i := from; i < to; i++ {
+		token := f.tokens[i]
+
+		space := i > from
+		if i > from && (f.tokens[i-1].Type == tks.LeftParen || f.tokens[i-1].Type == tks.LeftSquareParen) {
+			space = false
+		}
+		if token.Type == tks.RightParen || token.Type == tks.RightSquareParen || token.Type == tks.Comma {
+			space = false
+		}
+
+		if space {
+			f.write(" ")
+		}
+		f.write(token.Raw)
+	}
+}
+
+// options writes the options list starting at the given index, and returns the index after the list. Lists with
+// a single option are written inline, longer lists are written with one option per line and the values aligned.
+func (f *rootFormatter) options(idx int, indent string) int {
+	closing := f.matching(idx)
+
+	items, pending := make([]*optionItem, 0), make([]string, 0)
+	var current *optionItem
+	expectName, expectValue, comments := true, false, false
+	for i := idx + 1; i < closing; i++ {
+		token := f.tokens[i]
+
+		switch {
+		case token.Type == tks.Comment:
+			comments = true
+			if current != nil && !strings.Contains(token.Leading, "\n") {
+				current.trailing = append(current.trailing, token.Raw)
+			} else {
+				pending = append(pending, token.Raw)
+			}
+		case token.Type == tks.Comma:
+			expectName = true
+		case token.Type == tks.Equal:
+			expectValue = true
+		case expectValue:
+			current.value = token.Raw
+			expectValue = false
+		case expectName:
+			current = &optionItem{name: token.Raw, leading: pending}
+			items = append(items, current)
+			pending, expectName = make([]string, 0), false
+		default:
+		}
+	}
+
+	if len(items) <= 1 && !comments {
+		f.write(" [")
+		for _, item := range items {
+			f.write(item.name)
+			if item.value != "" {
+				f.write(" = " + item.value)
+			}
+		}
+		f.write("]")
+
+		return closing + 1
+	}
+
+	width := 0
+	for _, item := range items {
+		if item.value != "" {
+			width = max(width, len(item.name))
+		}
+	}
+
+	f.write(" [")
+	for i, item := range items {
+		for _, comment := range item.leading {
+			f.line(indent+defaultIndent, comment)
+		}
+
+		text := item.name
+		if item.value != "" {
+			text += strings.Repeat(" ", width-len(item.name)) + " = " + item.value
+		}
+		if i < len(items)-1 {
+			text += ","
+		}
+
+		f.line(indent+defaultIndent, text)
+		for _, comment := range item.trailing {
+			f.write(" " + comment)
+		}
+	}
+	for _, comment := range pending {
+		f.line(indent+defaultIndent, comment)
+	}
+	f.line(indent, "]")
+
+	return closing + 1
+}
+
+// section writes a single session section. Qualifiers are kept on the line of the keyword, and each entry is
+// written on its own line.
+func (f *rootFormatter) section(section *SyntaxNode, indent string) {
+	f.line(indent, f.tokens[section.Start].Raw)
+
+	i := section.Start + 1
+	for i < section.End {
+		token := f.tokens[i]
+
+		switch {
+		case token.Type == tks.Comment:
+			f.comment(token, indent+defaultIndent)
+			i++
+		case section.Kind == Description:
+			f.write(" " + token.Raw)
+			i++
+		case token.Type == tks.LeftSquareParen && (section.Kind == Options || section.Kind == Theories):
+			i = f.options(i, indent)
+		case token.Type == tks.LeftParen || token.Type == tks.LeftSquareParen:
+			// qualifiers such as '(in dir)' directly follow the keyword, '(global)' follows an entry
+			closing := f.matching(i)
+			f.write(" ")
+			f.inline(i, closing+1)
+			i = closing + 1
+		default:
+			f.line(indent+defaultIndent, token.Raw)
+			i++
+		}
+	}
+}
+
+// node writes a single top level construct.
+func (f *rootFormatter) node(node *SyntaxNode) {
+	headerEnd := node.End
+	if len(node.Children) > 0 {
+		headerEnd = node.Children[0].Start
+		for f.tokens[headerEnd-1].Type == tks.Comment {
+			headerEnd--
+		}
+	}
+
+	f.line("", "")
+	f.inline(node.Start, headerEnd)
+
+	pos := headerEnd
+	for _, section := range node.Children {
+		for ; pos < section.Start; pos++ {
+			f.comment(f.tokens[pos], defaultIndent)
+		}
+
+		f.section(section, defaultIndent)
+		pos = section.End
+	}
+}
+
+// Format formats the ROOT file represented by the syntax tree into the canonical layout. Sessions are separated by
+// blank lines, sections are written on their own lines in the order enforced by the grammar, options lists are
+// aligned, and every entry of a section is written on its own line. Comments are kept.
+func (s *RootSyntax) Format() string {
+	f := &rootFormatter{tokens: s.Tokens}
+
+	pos, afterNode := 0, false
+	gap := func(to int) {
+		for ; pos < to; pos++ {
+			token := s.Tokens[pos]
+			if f.builder.Len() > 0 && strings.Contains(token.Leading, "\n") && (afterNode || blankBefore(token)) {
+				f.write("\n")
+			}
+
+			f.comment(token, "")
+			// a comment on its own line is attached to the construct that follows it
+			if strings.Contains(token.Leading, "\n") {
+				afterNode = false
+			}
+		}
+	}
+
+	for _, node := range s.Nodes {
+		gap(node.Start)
+		if f.builder.Len() > 0 && (afterNode || blankBefore(s.Tokens[node.Start])) {
+			f.write("\n")
+		}
+
+		f.node(node)
+		pos, afterNode = node.End, true
+	}
+	gap(len(s.Tokens))
+
+	if f.builder.Len() == 0 {
+		return ""
+	}
+	return f.builder.String() + "\n"
+}
+
+// FormatRootFile formats the given ROOT file into the canonical layout, see RootSyntax.Format.
+func FormatRootFile(reader io.Reader) (string, error) {
+	syntax, err := ParseRootSyntax(reader)
+	if err != nil {
+		return "", err
+	}
+
+	return syntax.Format(), nil
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const formatTestInput = `  (* sessions of the example *)
+chapter   Example
+session Foo(AFP)in "foo"=HOL+ description "Foo"
+    options [document=pdf,timeout=600, quick_and_dirty]   (* slow *)
+ sessions "HOL-Library" Bar
+  theories[document=false] Foo_Base(global)
+
+
+      Foo
+(* documents *)
+ document_files(in "doc") "root.tex"
+session Bar = HOL + theories Bar
+`
+
+const formatTestOutput = `(* sessions of the example *)
+chapter Example
+
+session Foo (AFP) in "foo" = HOL +
+  description "Foo"
+  options [
+    document = pdf,
+    timeout  = 600,
+    quick_and_dirty
+  ] (* slow *)
+  sessions
+    "HOL-Library"
+    Bar
+  theories [document = false]
+    Foo_Base (global)
+    Foo
+  (* documents *)
+  document_files (in "doc")
+    "root.tex"
+
+session Bar = HOL +
+  theories
+    Bar
+`
+
+func TestFormatRootFile(t *testing.T) {
+	assert := asrt.New(t)
+
+	formatted, err := FormatRootFile(strings.NewReader(formatTestInput))
+	assert.NoError(err)
+	assert.Equal(formatTestOutput, formatted)
+
+	// formatting must not change the structure of the file, and formatted files must be left unchanged
+	expected, err := ParseRootFile(strings.NewReader(formatTestInput))
+	assert.NoError(err)
+	actual, err := ParseRootFile(strings.NewReader(formatted))
+	assert.NoError(err)
+	assert.Equal(len(expected.Chapters), len(actual.Chapters))
+	assert.Equal(expected.Chapters["Example"].Sessions[0].Theories[0].Entries, actual.Chapters["Example"].Sessions[0].Theories[0].Entries)
+
+	for _, input := range []string{formatTestOutput, syntaxTestRoot, editTestRoot} {
+		once, err := FormatRootFile(strings.NewReader(input))
+		assert.NoError(err)
+		twice, err := FormatRootFile(strings.NewReader(once))
+		assert.NoError(err)
+		assert.Equal(once, twice)
+	}
+
+	formatted, err = FormatRootFile(strings.NewReader(" \n"))
+	assert.NoError(err)
+	assert.Equal("", formatted)
+}
@@ -1,7 +1,7 @@
 package parser
 
 import (
-	"fmt"
+	"errors"
 	"github.com/tandemdude/proofman/pkg/parser/structure"
 	tks "github.com/tandemdude/proofman/pkg/parser/tokens"
 	"slices"
@@ -83,21 +83,14 @@ func (p *RootParser) maybeGroups() ([]*tks.Token, error) {
 	items := make([]*tks.Token, 0)
 
 	item := p.current()
-	for item.Type != tks.RightParen {
-		if item.Type != tks.Identifier && item.Type != tks.StringLiteral {
-			return nil, fmt.Errorf(
-				"Parsing failed at L%d\nExpected: Identifier, StringLiteral\nGot: %s",
-				item.LineNo, tks.TokenTypeName[item.Type],
-			)
+	for item == nil || item.Type != tks.RightParen {
+		if item == nil || (item.Type != tks.Identifier && item.Type != tks.StringLiteral) {
+			return nil, p.expected("Identifier, StringLiteral, ')'")
 		}
 
 		items = append(items, item)
 		p.currentIndex++
 		item = p.current()
-
-		if item == nil {
-			return nil, fmt.Errorf("Parsing failed at EOF\nExpected: ')'\nGot: EOF")
-		}
 	}
 
 	// This is guaranteed to be a right paren - we checked in the loop above
@@ -183,14 +176,14 @@ func (p *RootParser) maybeOptionsMap() (map[string]*tks.Token, error) {
 
 	optionsMap := make(map[string]*tks.Token)
 	current := p.current()
-	for current.Type != tks.RightSquareParen {
+	for current == nil || current.Type != tks.RightSquareParen {
 		optName, err := p.eat(tks.Identifier, tks.StringLiteral)
 		if err != nil {
 			return nil, err
 		}
 
 		var optValue *tks.Token = nil
-		if p.current().Type == tks.Equal {
+		if next := p.current(); next != nil && next.Type == tks.Equal {
 			_, _ = p.eat(tks.Equal)
 			optValue, err = p.eat(tks.Identifier, tks.StringLiteral, tks.NumberLiteral)
 			if err != nil {
@@ -202,7 +195,7 @@ func (p *RootParser) maybeOptionsMap() (map[string]*tks.Token, error) {
 
 		current = p.current()
 		if current == nil {
-			return nil, fmt.Errorf("Parsing failed at EOF\nExpected: ',', ']'\nGot: EOF")
+			return nil, p.expected("',', ']'")
 		}
 
 		// next token HAS to be a comma or a right square bracket
@@ -211,10 +204,10 @@ func (p *RootParser) maybeOptionsMap() (map[string]*tks.Token, error) {
 			continue
 		}
 
-		_, err = p.eat(tks.Comma)
-		if err != nil {
-			return nil, err
+		if current.Type != tks.Comma {
+			return nil, p.expected("',', ']'")
 		}
+		p.currentIndex++
 
 		current = p.current()
 	}
@@ -225,7 +218,7 @@ func (p *RootParser) maybeOptionsMap() (map[string]*tks.Token, error) {
 }
 
 func (p *RootParser) maybeOptions() (map[string]*tks.Token, error) {
-	optionsKw, err := p.eatKeyword(Options)
+	_, err := p.eatKeyword(Options)
 	if err != nil {
 		return nil, nil
 	}
@@ -235,7 +228,7 @@ func (p *RootParser) maybeOptions() (map[string]*tks.Token, error) {
 		return nil, err
 	}
 	if optionsMap == nil {
-		return nil, fmt.Errorf("Parsing failed at L%d\nExpected: option name", optionsKw.LineNo)
+		return nil, p.expected("'['")
 	}
 
 	return optionsMap, nil
@@ -312,7 +305,7 @@ func (p *RootParser) maybeDocumentFiles() (*structure.DocumentFiles, error) {
 			return nil, err
 		}
 		if dir == nil {
-			return nil, fmt.Errorf("FIXME")
+			return nil, p.expected("'in'")
 		}
 		documentFiles.Dir = dir.Value
 
@@ -349,7 +342,7 @@ func (p *RootParser) maybeExportFiles() (*structure.ExportFiles, error) {
 			return nil, err
 		}
 		if dir == nil {
-			return nil, fmt.Errorf("FIXME")
+			return nil, p.expected("'in'")
 		}
 		exportFiles.Dir = dir.Value
 
@@ -369,7 +362,7 @@ func (p *RootParser) maybeExportFiles() (*structure.ExportFiles, error) {
 		}
 
 		if strings.Contains(nat.Value, ".") {
-			return nil, fmt.Errorf("only natural numbers permitted")
+			return nil, p.errorAt(nat, "expected a natural number, found %s", describe(nat))
 		}
 
 		exportFiles.Nat = nat.Value
@@ -563,13 +556,14 @@ func NewRootParser(tokens []*tks.Token) *RootParser {
 	}
 
 	return &RootParser{
-		Parser:         Parser{newTokens, 0},
+		Parser:         Parser{tokens: newTokens},
 		currentChapter: "Unsorted",
 	}
 }
 
-func (p *RootParser) Parse() (*structure.RootStructure, error) {
-	parsedStructure := structure.RootStructure{
+// newRootStructure creates an empty structure containing only the default chapter.
+func newRootStructure() *structure.RootStructure {
+	parsedStructure := &structure.RootStructure{
 		Chapters:     make(map[string]*structure.Chapter),
 		ChapterOrder: make([]string, 0),
 	}
@@ -584,54 +578,132 @@ func (p *RootParser) Parse() (*structure.RootStructure, error) {
 	}
 	parsedStructure.ChapterOrder = append(parsedStructure.ChapterOrder, "Unsorted")
 
-	for p.currentIndex < len(p.tokens) {
-		currentToken, err := p.eatKeyword(ChapterDefinition, Chapter, Session)
+	return parsedStructure
+}
+
+// construct parses a single top level construct from the parse position into the given structure. The structure
+// is only modified if the whole construct was parsed successfully.
+func (p *RootParser) construct(parsedStructure *structure.RootStructure) error {
+	currentToken, err := p.eatKeyword(ChapterDefinition, Chapter, Session)
+	if err != nil {
+		return err
+	}
+
+	switch currentToken.Value {
+	case ChapterDefinition:
+		chapter, err := p.chapterDefinition()
+		if err != nil {
+			return err
+		}
+
+		if existing, ok := parsedStructure.Chapters[chapter.Name]; ok {
+			existing.Groups = chapter.Groups
+			existing.Description = chapter.Description
+		} else {
+			parsedStructure.Chapters[chapter.Name] = chapter
+		}
+
+	case Chapter:
+		chapter, err := p.chapter()
 		if err != nil {
+			return err
+		}
+
+		if _, ok := parsedStructure.Chapters[chapter.Name]; !ok {
+			parsedStructure.Chapters[chapter.Name] = chapter
+		}
+
+		if !slices.Contains(parsedStructure.ChapterOrder, chapter.Name) {
+			parsedStructure.ChapterOrder = append(parsedStructure.ChapterOrder, chapter.Name)
+		}
+
+		p.currentChapter = chapter.Name
+
+	case Session:
+		session, err := p.session()
+		if err != nil {
+			return err
+		}
+
+		parsedStructure.Chapters[p.currentChapter].Sessions = append(parsedStructure.Chapters[p.currentChapter].Sessions, session)
+
+	default:
+		panic("unexpected state encountered")
+	}
+
+	return nil
+}
+
+func (p *RootParser) Parse() (*structure.RootStructure, error) {
+	parsedStructure := newRootStructure()
+
+	for p.currentIndex < len(p.tokens) {
+		if err := p.construct(parsedStructure); err != nil {
 			return nil, err
 		}
+	}
 
-		switch currentToken.Value {
-		case ChapterDefinition:
-			chapter, err := p.chapterDefinition()
-			if err != nil {
-				return nil, err
-			}
+	return parsedStructure, nil
+}
 
-			if existing, ok := parsedStructure.Chapters[chapter.Name]; ok {
-				existing.Groups = chapter.Groups
-				existing.Description = chapter.Description
-			} else {
-				parsedStructure.Chapters[chapter.Name] = chapter
-			}
+// synchronise advances the parse position past the given index, to the next 'chapter_definition', 'chapter' or
+// 'session' keyword.
+func (p *RootParser) synchronise(index int) {
+	p.currentIndex = max(p.currentIndex, index+1)
 
-		case Chapter:
-			chapter, err := p.chapter()
-			if err != nil {
-				return nil, err
-			}
+	next := p.current()
+	for next != nil && (next.Type != tks.Identifier || !slices.Contains(topLevelKeywords, next.Value)) {
+		p.currentIndex++
+		next = p.current()
+	}
+}
 
-			if _, ok := parsedStructure.Chapters[chapter.Name]; !ok {
-				parsedStructure.Chapters[chapter.Name] = chapter
-			}
+// sessionName returns the name of the session defined by the keyword at the given index of the tokens, or an empty
+// string if the keyword does not start a named session definition. Comments between the keyword and the name are
+// skipped.
+func sessionName(tokens []*tks.Token, index int) string {
+	if index >= len(tokens) || tokens[index].Type != tks.Identifier || tokens[index].Value != Session {
+		return ""
+	}
 
-			if !slices.Contains(parsedStructure.ChapterOrder, chapter.Name) {
-				parsedStructure.ChapterOrder = append(parsedStructure.ChapterOrder, chapter.Name)
-			}
+	for _, token := range tokens[index+1:] {
+		if token.Type == tks.Comment {
+			continue
+		}
+		if token.Type == tks.Identifier || token.Type == tks.StringLiteral {
+			return token.Value
+		}
+		break
+	}
+
+	return ""
+}
 
-			p.currentChapter = chapter.Name
+// ParseRecovering parses the tokens in the same way as Parse, but does not stop at the first error. Instead, parsing
+// resumes from the next 'chapter_definition', 'chapter' or 'session' keyword. Every error encountered is returned,
+// alongside the structure of the constructs that were parsed successfully - any construct containing an error is
+// omitted from the structure, and the name of an omitted session is recorded within its Diagnostic.
+func (p *RootParser) ParseRecovering() (*structure.RootStructure, []*Diagnostic) {
+	parsedStructure := newRootStructure()
+	diagnostics := make([]*Diagnostic, 0)
 
-		case Session:
-			session, err := p.session()
-			if err != nil {
-				return nil, err
-			}
+	for p.currentIndex < len(p.tokens) {
+		start := p.currentIndex
 
-			parsedStructure.Chapters[p.currentChapter].Sessions = append(parsedStructure.Chapters[p.currentChapter].Sessions, session)
+		err := p.construct(parsedStructure)
+		if err == nil {
+			continue
+		}
 
-		default:
-			panic("unexpected state encountered")
+		var diagnostic *Diagnostic
+		if !errors.As(err, &diagnostic) {
+			diagnostic = p.errorAt(p.tokens[start], "%s", err)
 		}
+		diagnostic.Session = sessionName(p.tokens, start)
+		diagnostics = append(diagnostics, diagnostic)
+
+		p.synchronise(start)
 	}
 
-	return &parsedStructure, nil
+	return parsedStructure, diagnostics
 }
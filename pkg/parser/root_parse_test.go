@@ -0,0 +1,81 @@
+package parser
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const recoveringTestRoot = `chapter AFP
+
+session Foo = HOL +
+  theories Foo
+
+session Bar = HOL +
+  options [timeout = ]
+  theories Bar
+
+session = HOL +
+  theories Nameless
+
+chapter Examples
+
+session Baz = Foo +
+  theories Baz
+`
+
+func sessionNames(t *testing.T, source string) ([]string, []*Diagnostic) {
+	parsed, diagnostics, err := ParseRootFileRecovering("ROOT", strings.NewReader(source))
+	asrt.New(t).NoError(err)
+
+	names := make([]string, 0)
+	for _, chapter := range parsed.ChapterOrder {
+		for _, session := range parsed.Chapters[chapter].Sessions {
+			names = append(names, chapter+"/"+session.Name)
+		}
+	}
+
+	return names, diagnostics
+}
+
+func TestParseRootFileRecovering(t *testing.T) {
+	assert := asrt.New(t)
+
+	names, diagnostics := sessionNames(t, recoveringTestRoot)
+	assert.Equal([]string{"AFP/Foo", "Examples/Baz"}, names)
+	assert.Len(diagnostics, 2)
+	assert.Equal("ROOT:7:22", diagnostics[0].Position())
+	assert.Equal("ROOT:10:9", diagnostics[1].Position())
+	assert.Equal("Bar", diagnostics[0].Session)
+	assert.Equal("", diagnostics[1].Session)
+
+	// lexer errors skip only the construct containing them
+	names, diagnostics = sessionNames(t, "session Foo = HOL + theories Foo\nsession Bar = HOL + theories \"Bar\n")
+	assert.Equal([]string{"Unsorted/Foo"}, names)
+	assert.Len(diagnostics, 1)
+	assert.Equal("unterminated string literal", diagnostics[0].Message)
+	assert.Equal("Bar", diagnostics[0].Session)
+
+	// lexing resumes after a lexer error in the middle of the file, with the diagnostics in source order
+	names, diagnostics = sessionNames(t, "session Foo = HOL + theories Foo\n"+
+		"session Bar = HOL +\n  options [timeout = ?]\n  theories Bar\n"+
+		"session Baz = HOL + theories Baz\n"+
+		"session = HOL + theories Nameless\n"+
+		"session Qux = HOL + theories \"Qux\n")
+	assert.Equal([]string{"Unsorted/Foo", "Unsorted/Baz"}, names)
+	assert.Len(diagnostics, 3)
+	assert.Equal("ROOT:3:22", diagnostics[0].Position())
+	assert.Equal("Bar", diagnostics[0].Session)
+	assert.Equal("ROOT:6:9", diagnostics[1].Position())
+	assert.Equal("", diagnostics[1].Session)
+	assert.Equal("ROOT:7:30", diagnostics[2].Position())
+	assert.Equal("Qux", diagnostics[2].Session)
+
+	// valid files produce the same structure as the non-recovering parser
+	parsed, diagnostics, err := ParseRootFileRecovering("", strings.NewReader(syntaxTestRoot))
+	assert.NoError(err)
+	assert.Empty(diagnostics)
+	expected, err := ParseRootFile(strings.NewReader(syntaxTestRoot))
+	assert.NoError(err)
+	assert.Equal(expected, parsed)
+}
@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"github.com/tandemdude/proofman/pkg/parser/structure"
+	tks "github.com/tandemdude/proofman/pkg/parser/tokens"
+	"io"
+	"slices"
+	"strings"
+)
+
+var topLevelKeywords = []string{ChapterDefinition, Chapter, Session}
+
+var sectionKeywords = []string{
+	Description,
+	Options,
+	Sessions,
+	Directories,
+	Theories,
+	DocumentTheories,
+	DocumentFiles,
+	ExportFiles,
+	ExportClasspath,
+}
+
+// SyntaxToken is a single token of a ROOT file, alongside the whitespace that precedes it within the source.
+type SyntaxToken struct {
+	*tks.Token
+	Leading string
+}
+
+// SyntaxNode is a single construct within a ROOT file. At the top level this is a chapter definition, chapter or
+// session - the children of a session node are the sections of the session, such as 'theories'.
+//
+// A node spans the tokens [Start, End) of the syntax tree. Comments following the last token of a node are not
+// considered part of it.
+type SyntaxNode struct {
+	// Kind is the keyword that introduces the construct
+	Kind string
+	// Name is the name of the chapter or session, and is empty for session sections
+	Name     string
+	Start    int
+	End      int
+	Children []*SyntaxNode
+}
+
+// RootSyntax is a lossless syntax tree of a ROOT file. Unlike structure.RootStructure it retains every comment
+// and all whitespace, so that the original file can be reproduced exactly through String.
+type RootSyntax struct {
+	Tokens []*SyntaxToken
+	// Trailing is the whitespace following the final token of the file
+	Trailing string
+	Nodes    []*SyntaxNode
+}
+
+// nextToken returns the index of the first non-comment token after the given index, or the number of tokens
+// if there is no such token.
+func nextToken(tokens []*SyntaxToken, idx int) int {
+	idx++
+	for idx < len(tokens) && tokens[idx].Type == tks.Comment {
+		idx++
+	}
+
+	return idx
+}
+
+// buildSyntaxNodes groups the given tokens into their top level constructs and session sections. The tokens must
+// form a valid ROOT file - keywords can then be used to find the start of each construct, as the grammar never
+// permits a keyword to appear as a bare entry.
+func buildSyntaxNodes(tokens []*SyntaxToken) []*SyntaxNode {
+	nodes := make([]*SyntaxNode, 0)
+
+	var top, section *SyntaxNode
+	depth, inBody := 0, false
+	for i := nextToken(tokens, -1); i < len(tokens); i = nextToken(tokens, i) {
+		token := tokens[i]
+
+		if depth == 0 && token.Type == tks.Identifier && slices.Contains(topLevelKeywords, token.Value) {
+			top = &SyntaxNode{Kind: token.Value, Start: i, End: i + 1, Children: make([]*SyntaxNode, 0)}
+			section, inBody = nil, false
+			nodes = append(nodes, top)
+
+			// the name directly follows the keyword, and may itself be spelled like a keyword
+			if i = nextToken(tokens, i); i < len(tokens) {
+				top.Name = tokens[i].Value
+				top.End = i + 1
+			}
+			continue
+		}
+
+		switch token.Type {
+		case tks.LeftParen, tks.LeftSquareParen:
+			depth++
+		case tks.RightParen, tks.RightSquareParen:
+			depth--
+		case tks.Equal:
+			// sections may only appear after the equal sign of a session definition
+			if depth == 0 && top.Kind == Session {
+				inBody = true
+			}
+		case tks.Identifier:
+			if depth == 0 && inBody && slices.Contains(sectionKeywords, token.Value) {
+				section = &SyntaxNode{Kind: token.Value, Start: i, End: i + 1}
+				top.Children = append(top.Children, section)
+
+				// the value of a description may itself be spelled like a keyword
+				if token.Value == Description {
+					if i = nextToken(tokens, i); i < len(tokens) {
+						section.End = i + 1
+					}
+				}
+
+				top.End = section.End
+				continue
+			}
+		default:
+		}
+
+		top.End = i + 1
+		if section != nil {
+			section.End = i + 1
+		}
+	}
+
+	return nodes
+}
+
+func parseRootSyntax(source string) (*RootSyntax, error) {
+	// the syntax tree is only built for valid files, see buildSyntaxNodes
+	tokens, _, err := parseRoot("", source)
+	if err != nil {
+		return nil, err
+	}
+
+	syntax := &RootSyntax{Tokens: make([]*SyntaxToken, 0, len(tokens))}
+
+	end := 0
+	for _, token := range tokens {
+		syntax.Tokens = append(syntax.Tokens, &SyntaxToken{Token: token, Leading: source[end:token.Offset]})
+		end = token.Offset + len(token.Raw)
+	}
+	syntax.Trailing = source[end:]
+	syntax.Nodes = buildSyntaxNodes(syntax.Tokens)
+
+	return syntax, nil
+}
+
+// ParseRootSyntax parses the lossless syntax tree of a ROOT file. An error is returned if the file is not valid
+// ROOT syntax.
+func ParseRootSyntax(reader io.Reader) (*RootSyntax, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRootSyntax(string(content))
+}
+
+// Structure parses the semantic structure of the ROOT file represented by the syntax tree.
+func (s *RootSyntax) Structure() (*structure.RootStructure, error) {
+	tokens := make([]*tks.Token, 0, len(s.Tokens))
+	for _, token := range s.Tokens {
+		tokens = append(tokens, token.Token)
+	}
+
+	return NewRootParser(tokens).Parse()
+}
+
+// Session returns the node of the session with the given name, or nil if the file does not define such a session.
+func (s *RootSyntax) Session(name string) *SyntaxNode {
+	for _, node := range s.Nodes {
+		if node.Kind == Session && node.Name == name {
+			return node
+		}
+	}
+
+	return nil
+}
+
+// Text returns the source text of the given node, excluding the whitespace preceding its first token.
+func (s *RootSyntax) Text(node *SyntaxNode) string {
+	var builder strings.Builder
+	for i := node.Start; i < node.End; i++ {
+		if i > node.Start {
+			builder.WriteString(s.Tokens[i].Leading)
+		}
+		builder.WriteString(s.Tokens[i].Raw)
+	}
+
+	return builder.String()
+}
+
+// String prints the syntax tree, reproducing the source it was parsed from byte for byte.
+func (s *RootSyntax) String() string {
+	var builder strings.Builder
+	for _, token := range s.Tokens {
+		builder.WriteString(token.Leading)
+		builder.WriteString(token.Raw)
+	}
+	builder.WriteString(s.Trailing)
+
+	return builder.String()
+}
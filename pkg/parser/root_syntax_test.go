@@ -0,0 +1,80 @@
+package parser
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const syntaxTestRoot = `(* leading comment *)
+chapter_definition AFP (main) description "Archive of Formal Proofs"
+
+chapter AFP
+
+session "Foo" (AFP) in "foo" = "HOL-Library" +
+  description {* braced
+    description *}
+  options [timeout = 600,	document = pdf]  (* trailing comment *)
+  sessions
+    "HOL-Algebra"
+    Bar
+  theories [document = false]
+    Foo_Base (global)
+    \<comment> \<open>a cartouche comment\<close>
+    Foo
+  document_files (in "doc")
+    "root.tex"
+
+session Bar = HOL +
+  theories Bar
+`
+
+func TestRootSyntaxRoundTrip(t *testing.T) {
+	assert := asrt.New(t)
+
+	cases := []string{
+		syntaxTestRoot,
+		"",
+		"   \n\t(* only a comment *)\n",
+		strings.ReplaceAll(syntaxTestRoot, "\n", "\r\n"),
+	}
+
+	for _, input := range cases {
+		syntax, err := ParseRootSyntax(strings.NewReader(input))
+		assert.NoError(err)
+		assert.Equal(input, syntax.String())
+	}
+}
+
+func TestRootSyntaxNodes(t *testing.T) {
+	assert := asrt.New(t)
+
+	syntax, err := ParseRootSyntax(strings.NewReader(syntaxTestRoot))
+	assert.NoError(err)
+
+	kinds := make([]string, 0)
+	for _, node := range syntax.Nodes {
+		kinds = append(kinds, node.Kind+":"+node.Name)
+	}
+	assert.Equal([]string{"chapter_definition:AFP", "chapter:AFP", "session:Foo", "session:Bar"}, kinds)
+
+	foo := syntax.Session("Foo")
+	assert.NotNil(foo)
+
+	sections := make([]string, 0)
+	for _, section := range foo.Children {
+		sections = append(sections, section.Kind)
+	}
+	assert.Equal([]string{"description", "options", "sessions", "theories", "document_files"}, sections)
+	assert.Equal("options [timeout = 600,\tdocument = pdf]", syntax.Text(foo.Children[1]))
+	assert.Equal("document_files (in \"doc\")\n    \"root.tex\"", syntax.Text(foo.Children[4]))
+
+	assert.Equal("session Bar = HOL +\n  theories Bar", syntax.Text(syntax.Session("Bar")))
+	assert.Nil(syntax.Session("Baz"))
+
+	expected, err := ParseRootFile(strings.NewReader(syntaxTestRoot))
+	assert.NoError(err)
+	actual, err := syntax.Structure()
+	assert.NoError(err)
+	assert.Equal(expected, actual)
+}
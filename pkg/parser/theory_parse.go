@@ -26,7 +26,7 @@ func NewTheoryParser(tokens []*tks.Token) *TheoryParser {
 	}
 
 	return &TheoryParser{
-		Parser: Parser{newTokens, 0},
+		Parser: Parser{tokens: newTokens},
 	}
 }
 
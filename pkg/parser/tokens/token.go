@@ -28,15 +28,25 @@ var TokenTypeName = map[TokenType]string{
 	Plus:             "'+'",
 	LeftParen:        "'('",
 	RightParen:       "')'",
-	LeftSquareParen:  "'('",
-	RightSquareParen: "')'",
+	LeftSquareParen:  "'['",
+	RightSquareParen: "']'",
 	Comma:            "','",
 	Hash:             "'#'",
 	Asterisk:         "'*'",
 }
 
 type Token struct {
-	Type   TokenType
-	Value  string
-	LineNo int
+	Type  TokenType
+	Value string
+
+	// File is the name of the file the token was lexed from, which may be empty
+	File string
+	// Offset is the byte offset of the start of the token within the lexed source
+	Offset int
+	// Line is the 1-based line of the start of the token
+	Line int
+	// Column is the 1-based column of the start of the token, counted in characters
+	Column int
+	// Raw is the token exactly as it appears within the lexed source, including any quotes or comment delimiters
+	Raw string
 }
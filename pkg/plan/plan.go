@@ -0,0 +1,98 @@
+package plan
+
+import (
+	"errors"
+	"github.com/tandemdude/proofman/pkg/config"
+	"github.com/tandemdude/proofman/pkg/graph"
+	"maps"
+	"slices"
+)
+
+var (
+	ErrCannotOrderPackages = errors.New("cannot order packages")
+	ErrCannotOrderSessions = errors.New("cannot order sessions")
+)
+
+// Session is a single session within a plan, alongside the package that provides it. Sessions defined by the
+// project itself have an empty package.
+type Session struct {
+	Name    string
+	Package string
+}
+
+// Plan is the order that packages must be installed, and sessions must be built, in. Each level only depends
+// on the levels before it, so everything within a single level can be processed in parallel.
+type Plan struct {
+	Packages [][]string
+	Sessions [][]Session
+}
+
+// sessionDependencies returns every session that the given session depends on, through its parent session, its
+// ROOT sessions block or its theory imports.
+func sessionDependencies(session config.Session) []string {
+	dependencies := slices.Concat(session.Requires, session.Imports)
+	if session.Parent != "" {
+		dependencies = append(dependencies, session.Parent)
+	}
+
+	return dependencies
+}
+
+// New creates the plan for the given packages, which must include every package they require, and the sessions
+// defined by the project. Dependencies on sessions that are not provided by any of the packages, such as the
+// Isabelle builtin sessions, are ignored.
+func New(packages map[string]*config.ProofmanConfig, projectSessions []config.Session) (*Plan, error) {
+	packageGraph := make(graph.Graph, len(packages))
+	sessionPackages := make(map[string]string)
+	sessions := make(map[string]config.Session)
+
+	for _, name := range slices.Sorted(maps.Keys(packages)) {
+		manifest := packages[name]
+
+		packageGraph[name] = make([]string, 0, len(manifest.Project.Requires))
+		for _, req := range manifest.Project.Requires {
+			packageGraph[name] = append(packageGraph[name], req.Name)
+		}
+
+		for _, session := range manifest.Sessions {
+			sessionPackages[session.Name] = name
+			sessions[session.Name] = session
+		}
+	}
+	for _, session := range projectSessions {
+		sessionPackages[session.Name] = ""
+		sessions[session.Name] = session
+	}
+
+	packageLevels, err := packageGraph.Levels()
+	if err != nil {
+		return nil, errors.Join(err, ErrCannotOrderPackages)
+	}
+
+	sessionGraph := make(graph.Graph, len(sessions))
+	for name, session := range sessions {
+		sessionGraph[name] = make([]string, 0)
+		for _, dependency := range sessionDependencies(session) {
+			if _, ok := sessions[dependency]; ok && dependency != name {
+				sessionGraph[name] = append(sessionGraph[name], dependency)
+			}
+		}
+	}
+
+	sessionLevels, err := sessionGraph.Levels()
+	if err != nil {
+		return nil, errors.Join(err, ErrCannotOrderSessions)
+	}
+
+	plan := &Plan{Packages: packageLevels, Sessions: make([][]Session, 0, len(sessionLevels))}
+	for _, level := range sessionLevels {
+		planned := make([]Session, 0, len(level))
+		for _, name := range level {
+			planned = append(planned, Session{Name: name, Package: sessionPackages[name]})
+		}
+
+		plan.Sessions = append(plan.Sessions, planned)
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,55 @@
+package plan
+
+import (
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/pkg/config"
+	"testing"
+)
+
+func manifest(name string, requires []string, sessions ...config.Session) *config.ProofmanConfig {
+	cfg := &config.ProofmanConfig{Project: config.Project{Name: name}, Sessions: sessions}
+	for _, req := range requires {
+		cfg.Project.Requires = append(cfg.Project.Requires, config.Requirement{Name: req})
+	}
+
+	return cfg
+}
+
+func TestNewOrdersPackagesAndSessions(t *testing.T) {
+	assert := asrt.New(t)
+
+	packages := map[string]*config.ProofmanConfig{
+		"Base": manifest("Base", nil, config.Session{Name: "Base", Parent: "HOL"}),
+		"Lib": manifest("Lib", []string{"Base"},
+			config.Session{Name: "Lib_Core", Parent: "Base"},
+			config.Session{Name: "Lib", Parent: "Lib_Core"},
+		),
+		"Util": manifest("Util", []string{"Base"}, config.Session{Name: "Util", Parent: "HOL", Imports: []string{"Base"}}),
+	}
+	project := []config.Session{{Name: "Project", Parent: "Lib", Requires: []string{"Util"}}}
+
+	p, err := New(packages, project)
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal([][]string{{"Base"}, {"Lib", "Util"}}, p.Packages)
+	assert.Equal([][]Session{
+		{{"Base", "Base"}},
+		{{"Lib_Core", "Lib"}, {"Util", "Util"}},
+		{{"Lib", "Lib"}},
+		{{"Project", ""}},
+	}, p.Sessions)
+}
+
+func TestNewRejectsCycles(t *testing.T) {
+	assert := asrt.New(t)
+
+	packages := map[string]*config.ProofmanConfig{
+		"A": manifest("A", []string{"B"}),
+		"B": manifest("B", []string{"A"}),
+	}
+
+	_, err := New(packages, nil)
+	assert.ErrorIs(err, ErrCannotOrderPackages)
+}
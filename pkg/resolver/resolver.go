@@ -0,0 +1,388 @@
+package resolver
+
+import (
+	"fmt"
+	"github.com/tandemdude/proofman/pkg/config"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Source provides the package versions and manifests that the resolver chooses between. The manifests are
+// expected to be those produced by the 'index-afp' command.
+type Source interface {
+	// PackageVersions returns every available version of the given package.
+	PackageVersions(pkg string) ([]config.Version, error)
+	// Manifest returns the manifest of the given package at the given version.
+	Manifest(version config.Version, pkg string) (*config.ProofmanConfig, error)
+}
+
+// Step is a single link in the chain of requirements that caused a package to be required.
+type Step struct {
+	// RequiredBy is the name of the package that declared the requirement, or empty if it was declared by the project
+	RequiredBy  string
+	Version     config.Version
+	Requirement config.Requirement
+}
+
+func (s Step) String() string {
+	if s.RequiredBy == "" {
+		return "the project requires " + s.Requirement.String()
+	}
+
+	return fmt.Sprintf("%s %s requires %s", s.RequiredBy, s.Version, s.Requirement)
+}
+
+// ConflictError is returned when no version of a package satisfies all the requirements placed upon it. Each
+// element of Chains is the sequence of requirements, starting from the project, that led to one of the
+// conflicting requirements on Package.
+type ConflictError struct {
+	Package   string
+	Available []config.Version
+	Chains    [][]Step
+}
+
+func (e *ConflictError) Error() string {
+	var b strings.Builder
+
+	if len(e.Available) == 0 {
+		_, _ = fmt.Fprintf(&b, "no versions of %s are available, but it is required because:", e.Package)
+	} else {
+		_, _ = fmt.Fprintf(&b, "no version of %s satisfies all of its requirements:", e.Package)
+	}
+
+	for _, chain := range e.Chains {
+		for i, step := range chain {
+			b.WriteString("\n  ")
+			if i > 0 {
+				b.WriteString(strings.Repeat("  ", i-1) + "  -> ")
+			}
+			b.WriteString(step.String())
+		}
+	}
+
+	if len(e.Available) > 0 {
+		versions := make([]string, 0, len(e.Available))
+		for _, v := range e.Available {
+			versions = append(versions, v.String())
+		}
+		_, _ = fmt.Fprintf(&b, "\navailable versions of %s: %s", e.Package, strings.Join(versions, ", "))
+	}
+
+	return b.String()
+}
+
+type decision struct {
+	pkg     string
+	version config.Version
+	cause   *incoming
+}
+
+type incoming struct {
+	requirement config.Requirement
+	requiredBy  *decision
+}
+
+type state struct {
+	decisions map[string]*decision
+	incoming  map[string][]*incoming
+	// order records the packages in the order they were first required, so resolution is deterministic
+	order []string
+}
+
+func (s *state) clone() *state {
+	incoming := make(map[string][]*incoming, len(s.incoming))
+	for pkg, reqs := range s.incoming {
+		incoming[pkg] = slices.Clone(reqs)
+	}
+
+	return &state{
+		decisions: maps.Clone(s.decisions),
+		incoming:  incoming,
+		order:     slices.Clone(s.order),
+	}
+}
+
+func (s *state) require(in *incoming) {
+	name := in.requirement.Name
+	if _, ok := s.incoming[name]; !ok {
+		s.order = append(s.order, name)
+	}
+
+	s.incoming[name] = append(s.incoming[name], in)
+}
+
+// failure is a candidate version of a package that could not be extended into a solution. The search beneath it
+// only depended on the packages within footprint, each mapped to its state when the candidate was tried - see
+// describe. Any later candidate of the same package and version, where those packages are in the same state, fails
+// in the same way.
+type failure struct {
+	footprint map[string]string
+}
+
+type resolver struct {
+	source   Source
+	err      error
+	conflict *ConflictError
+	// depth is the number of decisions that had been made when conflict was found
+	depth    int
+	versions map[string][]config.Version
+	// preferred are the versions that are tried before any other version of the same package
+	preferred map[string]config.Version
+	// failures are the failed candidates, keyed by 'package @ version'
+	failures map[string][]failure
+	// reads are the packages whose state the current search has depended on
+	reads map[string]bool
+}
+
+// describe returns the state of the given package that the search depends on - its version if it has been decided,
+// otherwise the constraints placed upon it.
+func (s *state) describe(pkg string) string {
+	if d, ok := s.decisions[pkg]; ok {
+		return "== " + d.version.String()
+	}
+
+	constraints := make([]string, 0, len(s.incoming[pkg]))
+	for _, in := range s.incoming[pkg] {
+		constraints = append(constraints, in.requirement.Constraint.String())
+	}
+	slices.Sort(constraints)
+
+	return strings.Join(slices.Compact(constraints), ", ")
+}
+
+// failed returns the failure showing that the given candidate state fails, or nil if it is not known to fail.
+func (r *resolver) failed(key string, candidate *state) *failure {
+	for i, f := range r.failures[key] {
+		matches := true
+		for pkg, description := range f.footprint {
+			if candidate.describe(pkg) != description {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return &r.failures[key][i]
+		}
+	}
+
+	return nil
+}
+
+// search solves the given candidate state, which decided the package and version given by key. Candidates that
+// are known to fail are not searched again, and those that fail are recorded alongside the packages that the
+// search beneath them depended on.
+func (r *resolver) search(key string, candidate *state) (*state, bool) {
+	if f := r.failed(key, candidate); f != nil {
+		// the search that is skipped would have depended on the same packages
+		for pkg := range f.footprint {
+			r.reads[pkg] = true
+		}
+		return nil, false
+	}
+
+	outer := r.reads
+	r.reads = make(map[string]bool)
+	defer func() {
+		for pkg := range r.reads {
+			outer[pkg] = true
+		}
+		r.reads = outer
+	}()
+
+	solved, ok := r.solve(candidate)
+	if ok || r.err != nil {
+		return solved, ok
+	}
+
+	footprint := make(map[string]string, len(r.reads))
+	for pkg := range r.reads {
+		footprint[pkg] = candidate.describe(pkg)
+	}
+	r.failures[key] = append(r.failures[key], failure{footprint: footprint})
+
+	return nil, false
+}
+
+func chain(in *incoming) []Step {
+	steps := make([]Step, 0)
+	for in != nil {
+		step := Step{Requirement: in.requirement}
+		if in.requiredBy != nil {
+			step.RequiredBy = in.requiredBy.pkg
+			step.Version = in.requiredBy.version
+		}
+		steps = append(steps, step)
+
+		if in.requiredBy == nil {
+			break
+		}
+		in = in.requiredBy.cause
+	}
+
+	slices.Reverse(steps)
+	return steps
+}
+
+// recordConflict keeps the conflict found after the most decisions had been made, preferring the first of those
+// found. A conflict found early may only show that the newest versions were incompatible, whereas the deepest
+// conflict is the one that prevented the search from getting closest to a solution.
+func (r *resolver) recordConflict(s *state, pkg string, reqs []*incoming) {
+	if r.conflict != nil && len(s.decisions) <= r.depth {
+		return
+	}
+	r.depth = len(s.decisions)
+
+	chains := make([][]Step, 0, len(reqs))
+	for _, in := range reqs {
+		chains = append(chains, chain(in))
+	}
+
+	available := slices.Clone(r.versions[pkg])
+	slices.SortFunc(available, config.Version.Compare)
+
+	r.conflict = &ConflictError{Package: pkg, Available: available, Chains: chains}
+}
+
+func (r *resolver) packageVersions(pkg string) []config.Version {
+	if cached, ok := r.versions[pkg]; ok {
+		return cached
+	}
+
+	versions, err := r.source.PackageVersions(pkg)
+	if err != nil {
+		r.err = err
+		return nil
+	}
+
+	r.versions[pkg] = versions
+	return versions
+}
+
+func (r *resolver) solve(s *state) (*state, bool) {
+	next := ""
+	for _, pkg := range s.order {
+		if _, ok := s.decisions[pkg]; !ok {
+			next = pkg
+			break
+		}
+	}
+	if next == "" {
+		return s, true
+	}
+
+	r.reads[next] = true
+	reqs := s.incoming[next]
+	versions := r.packageVersions(next)
+	if r.err != nil {
+		return nil, false
+	}
+
+	candidates := make([]config.Version, 0, len(versions))
+	for _, v := range versions {
+		if slices.ContainsFunc(reqs, func(in *incoming) bool { return !in.requirement.Constraint.Matches(v) }) {
+			continue
+		}
+
+		candidates = append(candidates, v)
+	}
+	// prefer the newest compatible version, unless a compatible version was preferred by the caller
+	slices.SortFunc(candidates, func(a, b config.Version) int { return b.Compare(a) })
+	if preferred, ok := r.preferred[next]; ok {
+		if i := slices.IndexFunc(candidates, func(v config.Version) bool { return v.Compare(preferred) == 0 }); i > 0 {
+			candidates = slices.Insert(slices.Delete(candidates, i, i+1), 0, preferred)
+		}
+	}
+
+	if len(candidates) == 0 {
+		r.recordConflict(s, next, reqs)
+		return nil, false
+	}
+
+	for _, v := range candidates {
+		manifest, err := r.source.Manifest(v, next)
+		if err != nil {
+			r.err = err
+			return nil, false
+		}
+
+		candidate := s.clone()
+		d := &decision{pkg: next, version: v, cause: reqs[0]}
+		candidate.decisions[next] = d
+
+		consistent := true
+		for _, dep := range manifest.Project.Requires {
+			r.reads[dep.Name] = true
+			candidate.require(&incoming{requirement: dep, requiredBy: d})
+
+			// requirements on packages that have already been decided can be checked immediately
+			if decided, ok := candidate.decisions[dep.Name]; ok && !dep.Constraint.Matches(decided.version) {
+				r.recordConflict(candidate, dep.Name, candidate.incoming[dep.Name])
+				consistent = false
+				break
+			}
+		}
+		if !consistent {
+			continue
+		}
+
+		if solved, ok := r.search(next+" @ "+v.String(), candidate); ok {
+			return solved, true
+		}
+		if r.err != nil {
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// Resolve chooses one version of every package that is transitively required by the given requirements, such
+// that every requirement is satisfied. Newer versions are preferred over older ones. If no such choice exists then
+// a *ConflictError explaining the chain of requirements that caused the deepest conflict is returned.
+func Resolve(source Source, requires []config.Requirement) (map[string]config.Version, error) {
+	return ResolvePreferring(source, requires, nil)
+}
+
+// ResolvePreferring is Resolve, but the given versions are chosen over newer versions of the same packages
+// wherever they satisfy every requirement. This allows packages to be held at their locked versions, without
+// preventing them from changing when the requirements of other packages demand it. Preferred versions of packages
+// that are not required are ignored.
+func ResolvePreferring(
+	source Source,
+	requires []config.Requirement,
+	preferred map[string]config.Version,
+) (map[string]config.Version, error) {
+	r := &resolver{
+		source:    source,
+		versions:  make(map[string][]config.Version),
+		preferred: preferred,
+		failures:  make(map[string][]failure),
+		reads:     make(map[string]bool),
+	}
+
+	initial := &state{
+		decisions: make(map[string]*decision),
+		incoming:  make(map[string][]*incoming),
+		order:     make([]string, 0),
+	}
+	for _, req := range requires {
+		initial.require(&incoming{requirement: req})
+	}
+
+	solved, ok := r.solve(initial)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if !ok {
+		return nil, r.conflict
+	}
+
+	resolved := make(map[string]config.Version, len(solved.decisions))
+	for pkg, d := range solved.decisions {
+		resolved[pkg] = d.version
+	}
+
+	return resolved, nil
+}
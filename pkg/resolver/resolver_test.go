@@ -0,0 +1,249 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/tandemdude/proofman/pkg/config"
+	"testing"
+)
+
+func mustRequirement(input string) config.Requirement {
+	req, err := config.ParseRequirement(input)
+	if err != nil {
+		panic(err)
+	}
+
+	return req
+}
+
+// testSource maps "package @ version" to the requirements of that package version.
+type testSource map[string][]string
+
+func (t testSource) PackageVersions(pkg string) ([]config.Version, error) {
+	versions := make([]config.Version, 0)
+	for key := range t {
+		req := mustRequirement(key)
+		if req.Name == pkg {
+			v, _ := config.ParseVersion(req.Constraint.String())
+			versions = append(versions, v)
+		}
+	}
+
+	return versions, nil
+}
+
+func (t testSource) Manifest(version config.Version, pkg string) (*config.ProofmanConfig, error) {
+	reqs, ok := t[pkg+" @ "+version.String()]
+	if !ok {
+		return nil, errors.New("unknown package")
+	}
+
+	cfg := &config.ProofmanConfig{Project: config.Project{Name: pkg, Version: version.String()}}
+	for _, req := range reqs {
+		cfg.Project.Requires = append(cfg.Project.Requires, mustRequirement(req))
+	}
+
+	return cfg, nil
+}
+
+func requirements(raw ...string) []config.Requirement {
+	reqs := make([]config.Requirement, 0)
+	for _, r := range raw {
+		reqs = append(reqs, mustRequirement(r))
+	}
+
+	return reqs
+}
+
+func TestResolvePrefersNewestVersions(t *testing.T) {
+	assert := asrt.New(t)
+
+	source := testSource{
+		"A @ 2023-09-11": {"C @ 2023-09-11"},
+		"A @ 2024-05-12": {"C @ 2024-05-12"},
+		"C @ 2023-09-11": {},
+		"C @ 2024-05-12": {},
+	}
+
+	resolved, err := Resolve(source, requirements("A"))
+	assert.NoError(err)
+	assert.Equal("2024-05-12", resolved["A"].String())
+	assert.Equal("2024-05-12", resolved["C"].String())
+}
+
+func TestResolveBacktracks(t *testing.T) {
+	assert := asrt.New(t)
+
+	source := testSource{
+		"A @ 2023-09-11": {"C @ 2023-09-11"},
+		"A @ 2024-05-12": {"C @ 2024-05-12"},
+		"B @ 2023-09-11": {"C @ 2023-09-11"},
+		"C @ 2023-09-11": {},
+		"C @ 2024-05-12": {},
+	}
+
+	resolved, err := Resolve(source, requirements("A @ >=2023-01-01", "B"))
+	assert.NoError(err)
+	assert.Equal("2023-09-11", resolved["A"].String())
+	assert.Equal("2023-09-11", resolved["B"].String())
+	assert.Equal("2023-09-11", resolved["C"].String())
+}
+
+func TestResolvePreferring(t *testing.T) {
+	assert := asrt.New(t)
+
+	source := testSource{
+		"A @ 2023-09-11": {"C"},
+		"A @ 2024-05-12": {"C @ >=2024-05-12"},
+		"B @ 2023-09-11": {"C"},
+		"C @ 2023-09-11": {},
+		"C @ 2024-05-12": {},
+	}
+	locked := map[string]config.Version{}
+	for _, raw := range []string{"A @ 2023-09-11", "B @ 2023-09-11", "C @ 2023-09-11", "D @ 2023-09-11"} {
+		req := mustRequirement(raw)
+		locked[req.Name], _ = req.Constraint.Exact()
+	}
+
+	// transitive requirements are held at their preferred version
+	resolved, err := ResolvePreferring(source, requirements("A", "B"), locked)
+	assert.NoError(err)
+	assert.Equal("2023-09-11", resolved["A"].String())
+	assert.Equal("2023-09-11", resolved["C"].String())
+	assert.NotContains(resolved, "D")
+
+	// unless another package requires a newer version
+	delete(locked, "A")
+	resolved, err = ResolvePreferring(source, requirements("A", "B"), locked)
+	assert.NoError(err)
+	assert.Equal("2024-05-12", resolved["A"].String())
+	assert.Equal("2024-05-12", resolved["C"].String())
+}
+
+func TestResolveExplainsConflicts(t *testing.T) {
+	assert := asrt.New(t)
+
+	source := testSource{
+		"A @ 2024-05-12": {"C @ 2024-05-12"},
+		"B @ 2023-09-11": {"C @ 2023-09-11"},
+		"C @ 2023-09-11": {},
+		"C @ 2024-05-12": {},
+	}
+
+	_, err := Resolve(source, requirements("A @ >=2024", "B @ 2023-09-11"))
+
+	var conflict *ConflictError
+	if !assert.True(errors.As(err, &conflict)) {
+		return
+	}
+
+	assert.Equal("C", conflict.Package)
+	assert.Equal(`no version of C satisfies all of its requirements:
+  the project requires A @ >=2024
+    -> A 2024-05-12 requires C @ 2024-05-12
+  the project requires B @ 2023-09-11
+    -> B 2023-09-11 requires C @ 2023-09-11
+available versions of C: 2023-09-11, 2024-05-12`, conflict.Error())
+}
+
+func TestResolveReportsMissingPackages(t *testing.T) {
+	assert := asrt.New(t)
+
+	source := testSource{
+		"A @ 2024-05-12": {"Missing @ 2024-05-12"},
+	}
+
+	_, err := Resolve(source, requirements("A"))
+
+	var conflict *ConflictError
+	if assert.True(errors.As(err, &conflict)) {
+		assert.Equal("Missing", conflict.Package)
+		assert.Empty(conflict.Available)
+	}
+}
+
+func TestResolveReportsDeepestConflict(t *testing.T) {
+	assert := asrt.New(t)
+
+	// the newest version of A conflicts with B straight away, but the real cause is the missing version of E that
+	// the only other version of A requires
+	source := testSource{
+		"A @ 2023-09-11": {"D"},
+		"A @ 2024-05-12": {"C @ 2024-05-12"},
+		"B @ 2023-09-11": {"C @ 2023-09-11"},
+		"C @ 2023-09-11": {},
+		"C @ 2024-05-12": {},
+		"D @ 2023-09-11": {"E @ 2024-05-12"},
+		"E @ 2023-09-11": {},
+	}
+
+	_, err := Resolve(source, requirements("A", "B"))
+
+	var conflict *ConflictError
+	if !assert.True(errors.As(err, &conflict)) {
+		return
+	}
+
+	assert.Equal("E", conflict.Package)
+	assert.Equal(`no version of E satisfies all of its requirements:
+  the project requires A
+    -> A 2023-09-11 requires D
+      -> D 2023-09-11 requires E @ 2024-05-12
+available versions of E: 2023-09-11`, conflict.Error())
+}
+
+// countingSource counts the manifests read from the wrapped source.
+type countingSource struct {
+	testSource
+	manifests int
+}
+
+func (c *countingSource) Manifest(version config.Version, pkg string) (*config.ProofmanConfig, error) {
+	c.manifests++
+	return c.testSource.Manifest(version, pkg)
+}
+
+func TestResolveMemoisesFailures(t *testing.T) {
+	assert := asrt.New(t)
+
+	// every combination of the versions of the P packages fails in the same way, which is only searched once
+	source := &countingSource{testSource: testSource{"Z @ 2024-05-12": {"Missing"}}}
+	requires := make([]string, 0)
+	for i := range 12 {
+		name := fmt.Sprintf("P%d", i)
+		source.testSource[name+" @ 2023-09-11"] = []string{}
+		source.testSource[name+" @ 2024-05-12"] = []string{}
+		requires = append(requires, name)
+	}
+
+	_, err := Resolve(source, requirements(append(requires, "Z")...))
+
+	var conflict *ConflictError
+	if assert.True(errors.As(err, &conflict)) {
+		assert.Equal("Missing", conflict.Package)
+	}
+	assert.Less(source.manifests, 100)
+}
+
+func TestResolveMemoisedFailuresDependOnState(t *testing.T) {
+	assert := asrt.New(t)
+
+	// C fails beneath the newest version of A, as D requires the older version of B - that failure must not be
+	// reused once the older version of A has chosen the older version of B
+	source := testSource{
+		"A @ 2023-09-11": {"B @ 2023-09-11", "C"},
+		"A @ 2024-05-12": {"B @ 2024-05-12", "C"},
+		"B @ 2023-09-11": {},
+		"B @ 2024-05-12": {},
+		"C @ 2024-05-12": {"D"},
+		"D @ 2024-05-12": {"B @ 2023-09-11"},
+	}
+
+	resolved, err := Resolve(source, requirements("A"))
+	assert.NoError(err)
+	assert.Equal("2023-09-11", resolved["A"].String())
+	assert.Equal("2023-09-11", resolved["B"].String())
+	assert.Equal("2024-05-12", resolved["C"].String())
+	assert.Equal("2024-05-12", resolved["D"].String())
+}
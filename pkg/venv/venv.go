@@ -0,0 +1,117 @@
+package venv
+
+import (
+	"errors"
+	"github.com/tandemdude/proofman/internal"
+	"github.com/tandemdude/proofman/pkg/config"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var ErrNoVirtualEnvironment = errors.New("no virtual environment found - run 'proofman init' first")
+
+// DepsPath returns the directory that installed packages are placed into. This is the directory
+// registered with Isabelle through the 'isabelle_directory' setting created by 'proofman init'.
+func DepsPath(projectDirectory string) string {
+	return filepath.Join(projectDirectory, internal.VenvDirName, internal.DepsDirName)
+}
+
+func ensureDepsPath(projectDirectory string) (string, error) {
+	depsPath := DepsPath(projectDirectory)
+
+	exists, err := internal.PathExists(depsPath)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", ErrNoVirtualEnvironment
+	}
+
+	return depsPath, nil
+}
+
+// Installed returns the manifests of all the packages currently installed into the virtual environment.
+func Installed(projectDirectory string) (map[string]*config.ProofmanConfig, error) {
+	depsPath, err := ensureDepsPath(projectDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(depsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make(map[string]*config.ProofmanConfig)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		cfg, err := config.FromFile(filepath.Join(depsPath, entry.Name()))
+		if err != nil {
+			return nil, errors.Join(err, errors.New("invalid installed package "+entry.Name()))
+		}
+
+		installed[entry.Name()] = cfg
+	}
+
+	return installed, nil
+}
+
+// Install copies the package at sourcePath into the virtual environment, replacing
+// any existing installation of the package.
+func Install(projectDirectory, name, sourcePath string) error {
+	depsPath, err := ensureDepsPath(projectDirectory)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(depsPath, name)
+	if err = os.RemoveAll(target); err != nil {
+		return err
+	}
+
+	return internal.CopyDirectory(sourcePath, target)
+}
+
+// Remove deletes an installed package from the virtual environment.
+func Remove(projectDirectory, name string) error {
+	depsPath, err := ensureDepsPath(projectDirectory)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(filepath.Join(depsPath, name))
+}
+
+// WriteRoots regenerates the ROOTS file of the dependencies directory so that Isabelle can
+// discover the sessions of every installed package.
+func WriteRoots(projectDirectory string) error {
+	depsPath, err := ensureDepsPath(projectDirectory)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(depsPath)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+
+	content := strings.Join(names, "\n")
+	if len(names) > 0 {
+		content += "\n"
+	}
+
+	return internal.WriteFile(filepath.Join(depsPath, "ROOTS"), content, false)
+}